@@ -0,0 +1,372 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bicepparam parses the subset of the .bicepparam language used by pipelines: a using declaration, and
+// param declarations assigning literals, arrays and objects. Files are parsed after preprocessing, so that broken
+// quoting or an empty substitution is found before the Bicep compiler runs on a deployment agent.
+package bicepparam
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Position locates a token in a file, starting from 1.
+type Position struct {
+	Line, Column int
+}
+
+// File is a parsed .bicepparam file.
+type File struct {
+	// Using is the path to the Bicep template the parameters are for, relative to the file.
+	Using    string
+	UsingPos Position
+
+	Params []Param
+}
+
+// Param is a param declaration. Values are strings, int64s, bools, nil, []any or map[string]any.
+type Param struct {
+	Name     string
+	Value    any
+	Position Position
+}
+
+// Param returns the declaration of the named parameter, if there is one.
+func (f *File) Param(name string) (Param, bool) {
+	for _, param := range f.Params {
+		if param.Name == name {
+			return param, true
+		}
+	}
+	return Param{}, false
+}
+
+// Values returns the value of every declared parameter.
+func (f *File) Values() map[string]any {
+	out := map[string]any{}
+	for _, param := range f.Params {
+		out[param.Name] = param.Value
+	}
+	return out
+}
+
+// Error describes a problem at a position in a .bicepparam file.
+type Error struct {
+	// File is the file the content was read from, if known.
+	File string
+	Position
+	Message string
+}
+
+func (e *Error) Error() string {
+	location := fmt.Sprintf("%d:%d", e.Line, e.Column)
+	if e.File != "" {
+		location = e.File + ":" + location
+	}
+	return fmt.Sprintf("%s: %s", location, e.Message)
+}
+
+// ErrorList records every problem found in a file.
+type ErrorList []*Error
+
+func (l ErrorList) Error() string {
+	var messages []string
+	for _, err := range l {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// ParseFile reads and parses a .bicepparam file. Files holding template expressions must be preprocessed first;
+// use Parse on the preprocessed content instead.
+func ParseFile(path string) (*File, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	return Parse(path, content)
+}
+
+// Parse parses the content of a .bicepparam file, naming the file in errors if it is known. Parsing stops at the first
+// syntax error; otherwise, every duplicated param is reported. Errors are an ErrorList.
+func Parse(file string, content []byte) (*File, error) {
+	p := &parser{file: file, lexer: newLexer(content)}
+	out, err := p.parseFile()
+	if err != nil {
+		return nil, ErrorList{err}
+	}
+
+	var errs ErrorList
+	declared := map[string]Position{}
+	for _, param := range out.Params {
+		if previous, ok := declared[param.Name]; ok {
+			errs = append(errs, &Error{
+				File:     file,
+				Position: param.Position,
+				Message:  fmt.Sprintf("param %s is already declared at %d:%d", param.Name, previous.Line, previous.Column),
+			})
+			continue
+		}
+		declared[param.Name] = param.Position
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return out, nil
+}
+
+// CheckUsing ensures that the template the file is for exists. The using path is relative to the directory holding the
+// parameters file, so dir must be that directory, even when the file was preprocessed from elsewhere.
+func (f *File) CheckUsing(file, dir string) error {
+	if f.Using == "" {
+		return nil
+	}
+	target := f.Using
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(dir, target)
+	}
+	if _, err := os.Stat(target); err != nil {
+		return &Error{File: file, Position: f.UsingPos, Message: fmt.Sprintf("using target %s does not exist: %v", f.Using, err)}
+	}
+	return nil
+}
+
+type parser struct {
+	file  string
+	lexer *lexer
+
+	// peeked holds a token read ahead, if any
+	peeked *token
+}
+
+func (p *parser) next() (token, *Error) {
+	if p.peeked != nil {
+		tok := *p.peeked
+		p.peeked = nil
+		return tok, nil
+	}
+	tok, pos, err := p.lexer.next()
+	if err != nil {
+		return token{}, p.errorf(pos, "%v", err)
+	}
+	return tok, nil
+}
+
+func (p *parser) peek() (token, *Error) {
+	if p.peeked == nil {
+		tok, err := p.next()
+		if err != nil {
+			return token{}, err
+		}
+		p.peeked = &tok
+	}
+	return *p.peeked, nil
+}
+
+// skipNewlines consumes newlines, which separate statements, array items and object properties.
+func (p *parser) skipNewlines() *Error {
+	for {
+		tok, err := p.peek()
+		if err != nil {
+			return err
+		}
+		if tok.kind != tokenNewline {
+			return nil
+		}
+		p.peeked = nil
+	}
+}
+
+func (p *parser) errorf(pos Position, format string, args ...any) *Error {
+	return &Error{File: p.file, Position: pos, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) parseFile() (*File, *Error) {
+	out := &File{}
+	for {
+		if err := p.skipNewlines(); err != nil {
+			return nil, err
+		}
+		tok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case tok.kind == tokenEOF:
+			return out, nil
+		case tok.kind == tokenIdentifier && tok.text == "using":
+			if out.UsingPos.Line != 0 {
+				return nil, p.errorf(tok.pos, "using is already declared at %d:%d", out.UsingPos.Line, out.UsingPos.Column)
+			}
+			if len(out.Params) > 0 {
+				return nil, p.errorf(tok.pos, "using must be declared before any param")
+			}
+			target, err := p.next()
+			if err != nil {
+				return nil, err
+			}
+			if target.kind != tokenString {
+				return nil, p.errorf(target.pos, "expected the path to a template after using, found %s", target)
+			}
+			out.Using, out.UsingPos = target.text, tok.pos
+		case tok.kind == tokenIdentifier && tok.text == "param":
+			name, err := p.next()
+			if err != nil {
+				return nil, err
+			}
+			if name.kind != tokenIdentifier {
+				return nil, p.errorf(name.pos, "expected a param name, found %s", name)
+			}
+			equals, err := p.next()
+			if err != nil {
+				return nil, err
+			}
+			if equals.kind != tokenEquals {
+				return nil, p.errorf(equals.pos, "expected = after param %s, found %s", name.text, equals)
+			}
+			value, err := p.parseValue(fmt.Sprintf("param %s", name.text))
+			if err != nil {
+				return nil, err
+			}
+			out.Params = append(out.Params, Param{Name: name.text, Value: value, Position: name.pos})
+		default:
+			return nil, p.errorf(tok.pos, "expected using or param, found %s", tok)
+		}
+
+		end, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if end.kind != tokenNewline && end.kind != tokenEOF {
+			return nil, p.errorf(end.pos, "expected a new line, found %s", end)
+		}
+		if end.kind == tokenEOF {
+			return out, nil
+		}
+	}
+}
+
+// parseValue parses a literal, array or object, describing what the value is for in errors.
+func (p *parser) parseValue(what string) (any, *Error) {
+	tok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch tok.kind {
+	case tokenString:
+		return tok.text, nil
+	case tokenInteger:
+		return tok.integer, nil
+	case tokenIdentifier:
+		switch tok.text {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return nil, p.errorf(tok.pos, "expected a value for %s, found %s; only literals, arrays and objects are supported", what, tok)
+	case tokenLeftBracket:
+		return p.parseArray(what)
+	case tokenLeftBrace:
+		return p.parseObject(what)
+	default:
+		return nil, p.errorf(tok.pos, "expected a value for %s, found %s", what, tok)
+	}
+}
+
+func (p *parser) parseArray(what string) (any, *Error) {
+	items := []any{}
+	for i := 0; ; i++ {
+		if err := p.skipNewlines(); err != nil {
+			return nil, err
+		}
+		tok, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if tok.kind == tokenRightBracket {
+			p.peeked = nil
+			return items, nil
+		}
+		item, err := p.parseValue(fmt.Sprintf("%s[%d]", what, i))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if err := p.separator(tokenRightBracket, "]"); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseObject(what string) (any, *Error) {
+	properties := map[string]any{}
+	declared := map[string]Position{}
+	for {
+		if err := p.skipNewlines(); err != nil {
+			return nil, err
+		}
+		key, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if key.kind == tokenRightBrace {
+			return properties, nil
+		}
+		if key.kind != tokenIdentifier && key.kind != tokenString {
+			return nil, p.errorf(key.pos, "expected a property name in %s, found %s", what, key)
+		}
+		if previous, ok := declared[key.text]; ok {
+			return nil, p.errorf(key.pos, "property %s in %s is already declared at %d:%d", key.text, what, previous.Line, previous.Column)
+		}
+		declared[key.text] = key.pos
+		colon, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if colon.kind != tokenColon {
+			return nil, p.errorf(colon.pos, "expected : after property %s in %s, found %s", key.text, what, colon)
+		}
+		value, err := p.parseValue(fmt.Sprintf("%s.%s", what, key.text))
+		if err != nil {
+			return nil, err
+		}
+		properties[key.text] = value
+		if err := p.separator(tokenRightBrace, "}"); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// separator consumes the comma or new line after an item, leaving a closing token for the caller.
+func (p *parser) separator(closing tokenKind, closingText string) *Error {
+	tok, err := p.peek()
+	if err != nil {
+		return err
+	}
+	switch tok.kind {
+	case tokenComma, tokenNewline:
+		p.peeked = nil
+		return nil
+	case closing:
+		return nil
+	default:
+		return p.errorf(tok.pos, "expected a comma, a new line or %s, found %s", closingText, tok)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bicepparam_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Azure/ARO-Tools/pkg/bicepparam"
+)
+
+func TestParseFile(t *testing.T) {
+	file, err := bicepparam.ParseFile("testdata/configurations/region.bicepparam")
+	require.NoError(t, err)
+
+	require.Equal(t, "../templates/region.bicep", file.Using)
+	require.Equal(t, bicepparam.Position{Line: 1, Column: 1}, file.UsingPos)
+	if diff := cmp.Diff(map[string]any{
+		"regionRG":      "hcp-underlay",
+		"azCount":       int64(3),
+		"offset":        int64(-1),
+		"zoneRedundant": true,
+		"owner":         nil,
+		"escaped":       "it's a ${literal}\t\U0001F600",
+		"script":        "echo 'hello'\n",
+		"zones":         []any{"1", "2", "3"},
+		"tags": map[string]any{
+			"team":        "aro",
+			"cost-center": int64(42),
+			"nested":      map[string]any{"enabled": false},
+			"list":        []any{},
+		},
+	}, file.Values()); diff != "" {
+		t.Errorf("incorrect values (-want, +got): %v", diff)
+	}
+
+	param, ok := file.Param("azCount")
+	require.True(t, ok)
+	require.Equal(t, bicepparam.Position{Line: 7, Column: 7}, param.Position)
+
+	require.NoError(t, file.CheckUsing("testdata/configurations/region.bicepparam", "testdata/configurations"))
+	require.EqualError(t, file.CheckUsing("region.bicepparam", "testdata"), "region.bicepparam:1:1: using target ../templates/region.bicep does not exist: stat templates/region.bicep: no such file or directory")
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, testCase := range []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{
+			name:    "empty substitution",
+			content: "using 'main.bicep'\nparam azCount = \nparam other = 1\n",
+			err:     "region.bicepparam:2:17: expected a value for param azCount, found a new line",
+		},
+		{
+			name:    "empty substitution at the end of the file",
+			content: "param azCount = ",
+			err:     "region.bicepparam:1:17: expected a value for param azCount, found the end of the file",
+		},
+		{
+			name:    "broken quoting",
+			content: "param regionRG = 'hcp-underlay\nparam other = 1\n",
+			err:     "region.bicepparam:1:18: unterminated string",
+		},
+		{
+			name:    "unquoted string",
+			content: "param regionRG = hcp-underlay\n",
+			err:     "region.bicepparam:1:18: expected a value for param regionRG, found hcp; only literals, arrays and objects are supported",
+		},
+		{
+			name:    "two values on a line",
+			content: "param regionRG = 'a' 'b'\n",
+			err:     `region.bicepparam:1:22: expected a new line, found string "b"`,
+		},
+		{
+			name:    "interpolation",
+			content: "param name = 'prefix-${suffix}'\n",
+			err:     "region.bicepparam:1:22: string interpolation is not supported, only literals",
+		},
+		{
+			name:    "duplicate params",
+			content: "param a = 1\nparam b = 2\nparam a = 3\nparam b = 4\n",
+			err:     "region.bicepparam:3:7: param a is already declared at 1:7\nregion.bicepparam:4:7: param b is already declared at 2:7",
+		},
+		{
+			name:    "duplicate properties",
+			content: "param tags = {\n  team: 'a'\n  team: 'b'\n}\n",
+			err:     "region.bicepparam:3:3: property team in param tags is already declared at 2:3",
+		},
+		{
+			name:    "missing array separator",
+			content: "param zones = ['1' '2']\n",
+			err:     `region.bicepparam:1:20: expected a comma, a new line or ], found string "2"`,
+		},
+		{
+			name:    "unterminated array",
+			content: "param zones = [\n  '1'\n",
+			err:     "region.bicepparam:3:1: expected a value for param zones[1], found the end of the file",
+		},
+		{
+			name:    "invalid nested value",
+			content: "param tags = {\n  nested: {\n    value: =\n  }\n}\n",
+			err:     `region.bicepparam:3:12: expected a value for param tags.nested.value, found "="`,
+		},
+		{
+			name:    "using after a param",
+			content: "param a = 1\nusing 'main.bicep'\n",
+			err:     "region.bicepparam:2:1: using must be declared before any param",
+		},
+		{
+			name:    "unknown statement",
+			content: "var a = 1\n",
+			err:     "region.bicepparam:1:1: expected using or param, found var",
+		},
+		{
+			name:    "unterminated comment",
+			content: "param a = 1\n/* comment\n",
+			err:     "region.bicepparam:2:1: unterminated comment",
+		},
+		{
+			name:    "decimal",
+			content: "param a = 1.5\n",
+			err:     "region.bicepparam:1:12: unexpected character '.' in integer",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := bicepparam.Parse("region.bicepparam", []byte(testCase.content))
+			var errs bicepparam.ErrorList
+			require.ErrorAs(t, err, &errs)
+			require.EqualError(t, err, testCase.err)
+		})
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bicepparam
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNewline
+	tokenIdentifier
+	tokenString
+	tokenInteger
+	tokenEquals
+	tokenColon
+	tokenComma
+	tokenLeftBracket
+	tokenRightBracket
+	tokenLeftBrace
+	tokenRightBrace
+)
+
+var punctuation = map[rune]tokenKind{
+	'=': tokenEquals,
+	':': tokenColon,
+	',': tokenComma,
+	'[': tokenLeftBracket,
+	']': tokenRightBracket,
+	'{': tokenLeftBrace,
+	'}': tokenRightBrace,
+}
+
+type token struct {
+	kind tokenKind
+	pos  Position
+	// text is the identifier or the unquoted string
+	text    string
+	integer int64
+}
+
+// String describes the token for errors.
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "the end of the file"
+	case tokenNewline:
+		return "a new line"
+	case tokenIdentifier:
+		return t.text
+	case tokenString:
+		return fmt.Sprintf("string %q", t.text)
+	case tokenInteger:
+		return fmt.Sprintf("integer %d", t.integer)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+type lexer struct {
+	input  []rune
+	offset int
+	line   int
+	column int
+}
+
+func newLexer(content []byte) *lexer {
+	return &lexer{input: []rune(string(content)), line: 1, column: 1}
+}
+
+func (l *lexer) position() Position {
+	return Position{Line: l.line, Column: l.column}
+}
+
+func (l *lexer) peek(ahead int) rune {
+	if l.offset+ahead >= len(l.input) {
+		return 0
+	}
+	return l.input[l.offset+ahead]
+}
+
+func (l *lexer) advance() rune {
+	r := l.input[l.offset]
+	l.offset++
+	if r == '\n' {
+		l.line++
+		l.column = 1
+	} else {
+		l.column++
+	}
+	return r
+}
+
+func (l *lexer) done() bool {
+	return l.offset >= len(l.input)
+}
+
+// next returns the next token, or an error and the position where it occurred.
+func (l *lexer) next() (token, Position, error) {
+	for !l.done() {
+		r := l.peek(0)
+		switch {
+		case r == ' ' || r == '\t' || r == '\r':
+			l.advance()
+		case r == '/' && l.peek(1) == '/':
+			for !l.done() && l.peek(0) != '\n' {
+				l.advance()
+			}
+		case r == '/' && l.peek(1) == '*':
+			start := l.position()
+			l.advance()
+			l.advance()
+			for !(l.peek(0) == '*' && l.peek(1) == '/') {
+				if l.done() {
+					return token{}, start, fmt.Errorf("unterminated comment")
+				}
+				l.advance()
+			}
+			l.advance()
+			l.advance()
+		default:
+			return l.token()
+		}
+	}
+	return token{kind: tokenEOF, pos: l.position()}, Position{}, nil
+}
+
+func (l *lexer) token() (token, Position, error) {
+	pos := l.position()
+	r := l.peek(0)
+	switch {
+	case r == '\n':
+		l.advance()
+		return token{kind: tokenNewline, pos: pos}, Position{}, nil
+	case r == '\'' && l.peek(1) == '\'' && l.peek(2) == '\'':
+		return l.multilineString(pos)
+	case r == '\'':
+		return l.string(pos)
+	case r == '-' || unicode.IsDigit(r):
+		return l.integer(pos)
+	case r == '_' || unicode.IsLetter(r):
+		var text strings.Builder
+		for !l.done() && (l.peek(0) == '_' || unicode.IsLetter(l.peek(0)) || unicode.IsDigit(l.peek(0))) {
+			text.WriteRune(l.advance())
+		}
+		return token{kind: tokenIdentifier, pos: pos, text: text.String()}, Position{}, nil
+	}
+	if kind, ok := punctuation[r]; ok {
+		l.advance()
+		return token{kind: kind, pos: pos, text: string(r)}, Position{}, nil
+	}
+	return token{}, pos, fmt.Errorf("unexpected character %q", r)
+}
+
+func (l *lexer) string(pos Position) (token, Position, error) {
+	l.advance()
+	var text strings.Builder
+	for {
+		if l.done() || l.peek(0) == '\n' {
+			return token{}, pos, fmt.Errorf("unterminated string")
+		}
+		escape := l.position()
+		r := l.advance()
+		switch {
+		case r == '\'':
+			return token{kind: tokenString, pos: pos, text: text.String()}, Position{}, nil
+		case r == '$' && l.peek(0) == '{':
+			return token{}, escape, fmt.Errorf("string interpolation is not supported, only literals")
+		case r == '\\':
+			if l.done() {
+				return token{}, pos, fmt.Errorf("unterminated string")
+			}
+			switch e := l.advance(); e {
+			case '\\', '\'', '$':
+				text.WriteRune(e)
+			case 'n':
+				text.WriteRune('\n')
+			case 'r':
+				text.WriteRune('\r')
+			case 't':
+				text.WriteRune('\t')
+			case 'u':
+				value, err := l.unicodeEscape()
+				if err != nil {
+					return token{}, escape, err
+				}
+				text.WriteRune(value)
+			default:
+				return token{}, escape, fmt.Errorf("unknown escape sequence \\%c", e)
+			}
+		default:
+			text.WriteRune(r)
+		}
+	}
+}
+
+// unicodeEscape reads the code point in a \u{...} escape, after the u.
+func (l *lexer) unicodeEscape() (rune, error) {
+	if l.peek(0) != '{' {
+		return 0, fmt.Errorf("expected { after \\u")
+	}
+	l.advance()
+	var digits strings.Builder
+	for !l.done() && l.peek(0) != '}' && l.peek(0) != '\'' && l.peek(0) != '\n' {
+		digits.WriteRune(l.advance())
+	}
+	if l.peek(0) != '}' {
+		return 0, fmt.Errorf("unterminated unicode escape")
+	}
+	l.advance()
+	value, err := strconv.ParseUint(digits.String(), 16, 32)
+	if err != nil || value > unicode.MaxRune {
+		return 0, fmt.Errorf("invalid unicode escape \\u{%s}", digits.String())
+	}
+	return rune(value), nil
+}
+
+// multilineString reads a string between triple quotes, which has no escapes. A new line directly after the opening
+// quotes is dropped.
+func (l *lexer) multilineString(pos Position) (token, Position, error) {
+	for range 3 {
+		l.advance()
+	}
+	if l.peek(0) == '\r' && l.peek(1) == '\n' {
+		l.advance()
+	}
+	if l.peek(0) == '\n' {
+		l.advance()
+	}
+	var text strings.Builder
+	for !(l.peek(0) == '\'' && l.peek(1) == '\'' && l.peek(2) == '\'') {
+		if l.done() {
+			return token{}, pos, fmt.Errorf("unterminated multi-line string")
+		}
+		text.WriteRune(l.advance())
+	}
+	for range 3 {
+		l.advance()
+	}
+	return token{kind: tokenString, pos: pos, text: text.String()}, Position{}, nil
+}
+
+func (l *lexer) integer(pos Position) (token, Position, error) {
+	var text strings.Builder
+	if l.peek(0) == '-' {
+		text.WriteRune(l.advance())
+	}
+	for !l.done() && unicode.IsDigit(l.peek(0)) {
+		text.WriteRune(l.advance())
+	}
+	if !l.done() && (l.peek(0) == '.' || l.peek(0) == '_' || unicode.IsLetter(l.peek(0))) {
+		return token{}, l.position(), fmt.Errorf("unexpected character %q in integer", l.peek(0))
+	}
+	value, err := strconv.ParseInt(text.String(), 10, 64)
+	if err != nil {
+		return token{}, pos, fmt.Errorf("invalid integer %s", text.String())
+	}
+	return token{kind: tokenInteger, pos: pos, integer: value}, Position{}, nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// serviceSchema loads and compiles the service schema at most once, so that every resolver created by a provider
+// shares the work. It is safe for concurrent use.
+type serviceSchema struct {
+	// absolutePath locates the schema on disk or, when fsys is set, in that filesystem
+	absolutePath string
+	// content holds the schema, when it is provided in memory instead of loaded from absolutePath
+	content []byte
+	fsys    fs.FS
+
+	compileOnce sync.Once
+	compiled    *jsonschema.Schema
+	compileErr  error
+
+	documentOnce sync.Once
+	document     map[string]any
+	documentErr  error
+}
+
+// compile compiles the schema for validation.
+func (s *serviceSchema) compile() (*jsonschema.Schema, error) {
+	s.compileOnce.Do(func() {
+		loader := jsonschema.SchemeURLLoader{
+			"file": jsonschema.FileLoader{},
+		}
+		if s.fsys != nil {
+			loader = jsonschema.SchemeURLLoader{
+				fsScheme: fsLoader{fsys: s.fsys},
+			}
+		}
+		c := jsonschema.NewCompiler()
+		c.UseLoader(loader)
+		if s.content != nil {
+			document, err := jsonschema.UnmarshalJSON(bytes.NewReader(s.content))
+			if err != nil {
+				s.compileErr = fmt.Errorf("failed to unmarshal schema: %v", err)
+				return
+			}
+			if err := c.AddResource(s.location(), document); err != nil {
+				s.compileErr = fmt.Errorf("failed to add schema resource: %v", err)
+				return
+			}
+		}
+		s.compiled, s.compileErr = c.Compile(s.location())
+		if s.compileErr != nil {
+			s.compileErr = fmt.Errorf("failed to compile schema: %v", s.compileErr)
+		}
+	})
+	return s.compiled, s.compileErr
+}
+
+// rawDocument loads the schema as a raw document, for inspection of annotations. Callers must not mutate it.
+func (s *serviceSchema) rawDocument() (map[string]any, error) {
+	s.documentOnce.Do(func() {
+		if s.content != nil {
+			s.document, s.documentErr = unmarshalSchemaDocument(s.absolutePath, s.content)
+			return
+		}
+		if s.fsys != nil {
+			raw, err := fs.ReadFile(s.fsys, s.absolutePath)
+			if err != nil {
+				s.documentErr = fmt.Errorf("failed to read schema %s: %w", s.absolutePath, err)
+				return
+			}
+			s.document, s.documentErr = unmarshalSchemaDocument(s.absolutePath, raw)
+			return
+		}
+		s.document, s.documentErr = loadSchemaDocument(s.absolutePath)
+	})
+	return s.document, s.documentErr
+}
+
+// fsScheme identifies schemas read from a filesystem, so that references between them resolve within it.
+const fsScheme = "fs"
+
+// location is the URL under which the schema is compiled.
+func (s *serviceSchema) location() string {
+	if s.fsys != nil {
+		return fsScheme + ":///" + s.absolutePath
+	}
+	return s.absolutePath
+}
+
+// fsLoader loads schemas from a filesystem, given URLs of the form fs:///path/in/filesystem.
+type fsLoader struct {
+	fsys fs.FS
+}
+
+func (l fsLoader) Load(location string) (any, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	f, err := l.fsys.Open(strings.TrimPrefix(u.Path, "/"))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return jsonschema.UnmarshalJSON(f)
+}
@@ -0,0 +1,157 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Azure/ARO-Tools/pkg/config"
+	"github.com/Azure/ARO-Tools/pkg/config/configtest"
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+func TestResolverConcurrency(t *testing.T) {
+	provider, err := config.NewConfigProvider("../../testdata/config.yaml")
+	require.NoError(t, err)
+	replacements, err := config.NewConfigReplacements("public", "int", "uksouth", "1")
+	require.NoError(t, err)
+	resolver, err := provider.GetResolver(replacements)
+	require.NoError(t, err)
+	want, err := resolver.GetRegionConfiguration("uksouth")
+	require.NoError(t, err)
+
+	errs := make(chan error, 16)
+	for range 16 {
+		go func() {
+			cfg, err := resolver.GetRegionConfiguration("uksouth")
+			if err == nil {
+				// mutating what we're given must not affect what other callers see
+				cfg["mutated"] = true
+				err = resolver.ValidateSchema(want)
+			}
+			errs <- err
+		}()
+	}
+	for range 16 {
+		require.NoError(t, <-errs)
+	}
+
+	cfg, err := resolver.GetRegionConfiguration("uksouth")
+	require.NoError(t, err)
+	require.Empty(t, cmp.Diff(want, cfg))
+}
+
+func TestResolverSharesBaseMerge(t *testing.T) {
+	provider := configtest.NewBuilder().
+		WithDefaults(types.Configuration{"svc": map[string]any{"replicas": 1, "image": "default"}}).
+		WithEnvironment("public", "int", types.Configuration{"svc": map[string]any{"image": "int"}}).
+		WithRegion("public", "int", "uksouth", types.Configuration{"svc": map[string]any{"replicas": 3}}).
+		WithRegion("public", "int", "eastus", nil).
+		MustBuild(t)
+	resolver := configtest.Resolver(t, provider, "public", "int", "uksouth")
+
+	uksouth, err := resolver.GetRegionConfiguration("uksouth")
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"replicas": float64(3), "image": "int"}, uksouth["svc"])
+	uksouth["svc"].(map[string]any)["image"] = "mutated"
+
+	eastus, err := resolver.GetRegionConfiguration("eastus")
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"replicas": float64(1), "image": "int"}, eastus["svc"])
+	base, err := resolver.GetConfiguration()
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"replicas": float64(1), "image": "int"}, base["svc"])
+
+	// strict resolvers check the base, even after merging a region onto it
+	strict := configtest.NewBuilder().
+		WithDefaults(types.Configuration{"oldReplicas": 1}).
+		WithRegion("public", "int", "uksouth", nil).
+		WithSchema([]byte(`{"type": "object", "properties": {"oldReplicas": {"deprecated": true}}}`)).
+		WithProviderOptions(config.WithStrictDeprecations()).
+		MustBuild(t)
+	strictResolver := configtest.Resolver(t, strict, "public", "int", "uksouth")
+	var deprecationErr *config.DeprecationError
+	_, err = strictResolver.GetRegionConfiguration("uksouth")
+	require.ErrorAs(t, err, &deprecationErr)
+	_, err = strictResolver.GetConfiguration()
+	require.ErrorAs(t, err, &deprecationErr)
+}
+
+func BenchmarkGetResolver(b *testing.B) {
+	provider, err := config.NewConfigProvider("../../testdata/config.yaml")
+	require.NoError(b, err)
+	replacements, err := config.NewConfigReplacements("public", "int", "uksouth", "1")
+	require.NoError(b, err)
+
+	b.ResetTimer()
+	for range b.N {
+		if _, err := provider.GetResolver(replacements); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetRegionConfiguration(b *testing.B) {
+	provider, err := config.NewConfigProvider("../../testdata/config.yaml")
+	require.NoError(b, err)
+	replacements, err := config.NewConfigReplacements("public", "int", "uksouth", "1")
+	require.NoError(b, err)
+	resolver, err := provider.GetResolver(replacements)
+	require.NoError(b, err)
+
+	b.ResetTimer()
+	for range b.N {
+		if _, err := resolver.GetRegionConfiguration("uksouth"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkValidateSchema(b *testing.B) {
+	provider, err := config.NewConfigProvider("../../testdata/config.yaml")
+	require.NoError(b, err)
+	replacements, err := config.NewConfigReplacements("public", "int", "uksouth", "1")
+	require.NoError(b, err)
+	resolver, err := provider.GetResolver(replacements)
+	require.NoError(b, err)
+	cfg, err := resolver.GetRegionConfiguration("uksouth")
+	require.NoError(b, err)
+
+	b.ResetTimer()
+	for range b.N {
+		if err := resolver.ValidateSchema(cfg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkResolveAllContexts(b *testing.B) {
+	provider, err := config.NewConfigProvider("../../testdata/config.yaml")
+	require.NoError(b, err)
+
+	for _, parallelism := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("parallelism=%d", parallelism), func(b *testing.B) {
+			for range b.N {
+				if _, err := config.ResolveAllContextsInParallel(provider, parallelism); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/cobra"
+
+	"github.com/Azure/ARO-Tools/pkg/config"
+	"github.com/Azure/ARO-Tools/pkg/config/codegen"
+)
+
+func DefaultOptions() *RawOptions {
+	return &RawOptions{
+		Package:  "config",
+		TypeName: "Configuration",
+	}
+}
+
+func BindOptions(opts *RawOptions, cmd *cobra.Command) error {
+	cmd.Flags().StringVar(&opts.ConfigPath, "config", opts.ConfigPath, "Path to the configuration file whose schema to generate types for.")
+	cmd.Flags().StringVar(&opts.SchemaPath, "schema", opts.SchemaPath, "Path to the schema to generate types for, instead of the one the configuration references.")
+	cmd.Flags().StringVar(&opts.Package, "package", opts.Package, "Name of the package holding the generated code.")
+	cmd.Flags().StringVar(&opts.TypeName, "type", opts.TypeName, "Name of the type generated for the whole configuration.")
+	cmd.Flags().StringVar(&opts.OutputPath, "output", opts.OutputPath, "Path to write the generated code to. Defaults to stdout.")
+
+	for _, flag := range []string{
+		"config",
+		"schema",
+		"output",
+	} {
+		if err := cmd.MarkFlagFilename(flag); err != nil {
+			return fmt.Errorf("failed to mark flag %q as a file: %w", flag, err)
+		}
+	}
+	return nil
+}
+
+// RawOptions holds input values.
+type RawOptions struct {
+	ConfigPath string
+	SchemaPath string
+	Package    string
+	TypeName   string
+	OutputPath string
+}
+
+// validatedOptions is a private wrapper that enforces a call of Validate() before Complete() can be invoked.
+type validatedOptions struct {
+	*RawOptions
+}
+
+type ValidatedOptions struct {
+	// Embed a private pointer that cannot be instantiated outside of this package.
+	*validatedOptions
+}
+
+// completedOptions is a private wrapper that enforces a call of Complete() before the generation can be invoked.
+type completedOptions struct {
+	SchemaPath string
+	Schema     []byte
+	Package    string
+	TypeName   string
+
+	OutputPath string
+	Out        io.Writer
+}
+
+type Options struct {
+	// Embed a private pointer that cannot be instantiated outside of this package.
+	*completedOptions
+}
+
+func (o *RawOptions) Validate() (*ValidatedOptions, error) {
+	if o.ConfigPath == "" && o.SchemaPath == "" {
+		return nil, fmt.Errorf("the configuration file must be provided with --config, or the schema with --schema")
+	}
+	if o.Package == "" {
+		return nil, fmt.Errorf("the package name must be provided with --package")
+	}
+	if o.TypeName == "" {
+		return nil, fmt.Errorf("the type name must be provided with --type")
+	}
+
+	return &ValidatedOptions{
+		validatedOptions: &validatedOptions{
+			RawOptions: o,
+		},
+	}, nil
+}
+
+func (o *ValidatedOptions) Complete() (*Options, error) {
+	if o.SchemaPath != "" {
+		schema, err := os.ReadFile(o.SchemaPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema: %w", err)
+		}
+		return o.complete(o.SchemaPath, schema), nil
+	}
+
+	provider, err := config.NewConfigProvider(o.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	contexts, err := config.ResolveAllContexts(provider)
+	if err != nil {
+		return nil, err
+	}
+	if len(contexts) == 0 {
+		return nil, fmt.Errorf("configuration %s has no contexts to determine the schema with", o.ConfigPath)
+	}
+	schemaPath, err := contexts[0].Resolver.SchemaPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine schema path: %w", err)
+	}
+	// the resolver reads the schema wherever the provider loads it from
+	document, err := contexts[0].Resolver.SchemaDocument()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schema: %w", err)
+	}
+	if document == nil {
+		return nil, fmt.Errorf("configuration %s is not registered as using a schema", o.ConfigPath)
+	}
+	schema, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+	return o.complete(schemaPath, schema), nil
+}
+
+func (o *ValidatedOptions) complete(schemaPath string, schema []byte) *Options {
+	return &Options{
+		completedOptions: &completedOptions{
+			SchemaPath: schemaPath,
+			Schema:     schema,
+			Package:    o.Package,
+			TypeName:   o.TypeName,
+			OutputPath: o.OutputPath,
+			Out:        os.Stdout,
+		},
+	}
+}
+
+// Generate writes the Go types for the schema to the output file, or to stdout.
+func (opts *Options) Generate(ctx context.Context) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	generated, err := codegen.Generate(opts.Schema, codegen.Options{Package: opts.Package, TypeName: opts.TypeName})
+	if err != nil {
+		return fmt.Errorf("failed to generate types for %s: %w", opts.SchemaPath, err)
+	}
+
+	if opts.OutputPath == "" {
+		if _, err := opts.Out.Write(generated); err != nil {
+			return fmt.Errorf("failed to write generated code: %w", err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(opts.OutputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(opts.OutputPath, generated, 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", opts.OutputPath, err)
+	}
+	logger.Info("Generated configuration types.", "schema", opts.SchemaPath, "output", opts.OutputPath)
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Azure/ARO-Tools/pkg/config/cli/codegen"
+	"github.com/Azure/ARO-Tools/pkg/config/cli/get"
+	"github.com/Azure/ARO-Tools/pkg/config/cli/migrate"
+	"github.com/Azure/ARO-Tools/pkg/config/cli/mv"
+	"github.com/Azure/ARO-Tools/pkg/config/cli/render"
+	"github.com/Azure/ARO-Tools/pkg/config/cli/unused"
+	"github.com/Azure/ARO-Tools/pkg/config/cli/verify"
+)
+
+func NewCommand() (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:           "config",
+		Short:         "Manage service configuration files.",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	commands := []func() (*cobra.Command, error){
+		codegen.NewCommand,
+		get.NewCommand,
+		migrate.NewCommand,
+		mv.NewCommand,
+		render.NewCommand,
+		unused.NewCommand,
+		verify.NewCommand,
+	}
+	for _, newCmd := range commands {
+		c, err := newCmd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create subcommand: %w", err)
+		}
+		cmd.AddCommand(c)
+	}
+
+	return cmd, nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package get
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCommand() (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:           "get path",
+		Short:         "Print the value at a configuration path for one context or every context, and where it comes from.",
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	opts := DefaultOptions()
+	if err := BindOptions(opts, cmd); err != nil {
+		return nil, fmt.Errorf("failed to bind options: %w", err)
+	}
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer cancel()
+
+		opts.Path = args[0]
+		validated, err := opts.Validate()
+		if err != nil {
+			return err
+		}
+		completed, err := validated.Complete()
+		if err != nil {
+			return err
+		}
+		completed.Out = cmd.OutOrStdout()
+		return completed.Get(ctx)
+	}
+
+	return cmd, nil
+}
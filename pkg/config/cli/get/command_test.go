@@ -0,0 +1,159 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package get
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const configuration = `$metaSchema: config.meta.schema.v2.json
+defaults:
+  regionRG: hcp-underlay-{{ .ctx.regionShort }}
+  svc:
+    replicas: 1
+clouds:
+  public:
+    environments:
+      int:
+        defaults:
+          svc:
+            replicas: 2
+        regions:
+          eastus:
+            defaults:
+              svc:
+                replicas: 3
+          uksouth:
+            defaults:
+              svc:
+                digest: sha256:uksouth
+`
+
+func get(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd, err := NewCommand()
+	require.NoError(t, err)
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs(args)
+	err = cmd.Execute()
+	return out.String(), err
+}
+
+func TestGet(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(configuration), 0644))
+
+	for _, testCase := range []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "text",
+			args:     []string{"svc.replicas", "--cloud", "public", "--environment", "int", "--region", "eastus"},
+			expected: "public/int/eastus: 3\n",
+		},
+		{
+			name:     "provenance and overrides",
+			args:     []string{"svc.replicas", "--cloud", "public", "--environment", "int", "--region", "eastus", "--provenance", "--overrides"},
+			expected: "public/int/eastus: 3\n  default: 1\n  environment: 2\n  region: 3\n  overridden in regions: eastus\n",
+		},
+		{
+			name:     "not set",
+			args:     []string{"svc.digest", "--all"},
+			expected: "public/int/eastus: <not set>\npublic/int/uksouth: sha256:uksouth\n",
+		},
+		{
+			name:     "yaml",
+			args:     []string{"svc.digest", "--all", "--format", "yaml"},
+			expected: "- context: public/int/eastus\n  set: false\n- context: public/int/uksouth\n  set: true\n  value: sha256:uksouth\n",
+		},
+		{
+			name:     "json",
+			args:     []string{"svc.replicas", "--cloud", "public", "--environment", "int", "--region", "uksouth", "--format", "json", "--provenance"},
+			expected: "[\n  {\n    \"context\": \"public/int/uksouth\",\n    \"set\": true,\n    \"value\": 2,\n    \"provenance\": [\n      {\n        \"level\": \"default\",\n        \"value\": 1\n      },\n      {\n        \"level\": \"environment\",\n        \"value\": 2\n      }\n    ]\n  }\n]\n",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			out, err := get(t, append(testCase.args, "--config", configPath)...)
+			require.NoError(t, err)
+			require.Equal(t, testCase.expected, out)
+		})
+	}
+}
+
+func TestGetErrors(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(configuration), 0644))
+
+	for _, testCase := range []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "all with context",
+			args:     []string{"svc.replicas", "--all", "--cloud", "public"},
+			expected: "--all cannot be combined with --cloud, --environment or --region",
+		},
+		{
+			name:     "missing region",
+			args:     []string{"svc.replicas", "--cloud", "public", "--environment", "int"},
+			expected: "the region must be provided with --region, or every context queried with --all",
+		},
+		{
+			name:     "not set in context",
+			args:     []string{"svc.digest", "--cloud", "public", "--environment", "int", "--region", "eastus"},
+			expected: "svc.digest is not set in public/int/eastus",
+		},
+		{
+			name:     "not set in any context",
+			args:     []string{"svc.missing", "--all"},
+			expected: "svc.missing is not set in any context",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			out, err := get(t, append(testCase.args, "--config", configPath)...)
+			require.EqualError(t, err, testCase.expected)
+			require.Empty(t, out)
+		})
+	}
+}
+
+func TestGetRedactsSensitiveValues(t *testing.T) {
+	configPath := filepath.Join("..", "..", "testdata", "sensitivity", "config.yaml")
+	args := []string{"geneva.certificate", "--config", configPath, "--cloud", "public", "--environment", "int", "--region", "uksouth", "--provenance"}
+
+	// the schema marks geneva.certificate as sensitive, so neither the value nor any level setting it is printed
+	out, err := get(t, args...)
+	require.NoError(t, err)
+	require.Equal(t, "public/int/uksouth: <redacted>\n  default: <redacted>\n  environment: <redacted>\n", out)
+
+	out, err = get(t, append(args, "--show-sensitive")...)
+	require.NoError(t, err)
+	require.Equal(t, "public/int/uksouth: int-certificate\n  default: default-certificate\n  environment: int-certificate\n", out)
+
+	// keys matching a sensitive key pattern are redacted wherever they are, in every format
+	out, err = get(t, "db", "--config", configPath, "--all", "--format", "json")
+	require.NoError(t, err)
+	require.Contains(t, out, "\"adminPassword\": \"<redacted>\"")
+	require.NotContains(t, out, "default-password")
+}
@@ -0,0 +1,357 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package get
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/Azure/ARO-Tools/pkg/config"
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+const (
+	formatText = "text"
+	formatYAML = "yaml"
+	formatJSON = "json"
+)
+
+func DefaultOptions() *RawOptions {
+	return &RawOptions{
+		Format:               formatText,
+		SensitiveKeyPatterns: types.DefaultSensitiveKeyPatterns,
+	}
+}
+
+func BindOptions(opts *RawOptions, cmd *cobra.Command) error {
+	cmd.Flags().StringVar(&opts.ConfigPath, "config", opts.ConfigPath, "Path to the configuration file to query.")
+	cmd.Flags().StringVar(&opts.Cloud, "cloud", opts.Cloud, "Cloud of the context to resolve.")
+	cmd.Flags().StringVar(&opts.Environment, "environment", opts.Environment, "Environment of the context to resolve.")
+	cmd.Flags().StringVar(&opts.Region, "region", opts.Region, "Region of the context to resolve.")
+	cmd.Flags().BoolVar(&opts.All, "all", opts.All, "Resolve every context the configuration has records for, instead of one.")
+	cmd.Flags().BoolVar(&opts.Provenance, "provenance", opts.Provenance, "Print the value set by every level of overrides that sets it.")
+	cmd.Flags().BoolVar(&opts.Overrides, "overrides", opts.Overrides, "Print the regions of the cloud and environment whose overrides set the value.")
+	cmd.Flags().StringVar(&opts.Format, "format", opts.Format, "Output format, one of text, yaml or json.")
+	cmd.Flags().BoolVar(&opts.ShowSensitive, "show-sensitive", opts.ShowSensitive, "Print sensitive values instead of redacting them.")
+	cmd.Flags().StringSliceVar(&opts.SensitiveKeyPatterns, "sensitive-key-pattern", opts.SensitiveKeyPatterns, "Regular expression for the names of keys whose values are redacted, along with properties the schema marks as sensitive.")
+
+	for _, flag := range []string{
+		"config",
+	} {
+		if err := cmd.MarkFlagFilename(flag); err != nil {
+			return fmt.Errorf("failed to mark flag %q as a file: %w", flag, err)
+		}
+	}
+	return nil
+}
+
+// RawOptions holds input values.
+type RawOptions struct {
+	ConfigPath string
+
+	Cloud       string
+	Environment string
+	Region      string
+	All         bool
+
+	Provenance bool
+	Overrides  bool
+	Format     string
+
+	ShowSensitive        bool
+	SensitiveKeyPatterns []string
+
+	Path string
+}
+
+// validatedOptions is a private wrapper that enforces a call of Validate() before Complete() can be invoked.
+type validatedOptions struct {
+	*RawOptions
+}
+
+type ValidatedOptions struct {
+	// Embed a private pointer that cannot be instantiated outside of this package.
+	*validatedOptions
+}
+
+// completedOptions is a private wrapper that enforces a call of Complete() before the query can be invoked.
+type completedOptions struct {
+	Provider config.ConfigProvider
+
+	Cloud       string
+	Environment string
+	Region      string
+	All         bool
+
+	Path       string
+	Provenance bool
+	Overrides  bool
+	Format     string
+
+	ShowSensitive        bool
+	SensitiveKeyPatterns []string
+
+	Out io.Writer
+}
+
+type Options struct {
+	// Embed a private pointer that cannot be instantiated outside of this package.
+	*completedOptions
+}
+
+func (o *RawOptions) Validate() (*ValidatedOptions, error) {
+	if o.ConfigPath == "" {
+		return nil, fmt.Errorf("the configuration file must be provided with --config")
+	}
+	if o.Path == "" {
+		return nil, fmt.Errorf("the path to query must be provided")
+	}
+	if o.All {
+		if o.Cloud != "" || o.Environment != "" || o.Region != "" {
+			return nil, fmt.Errorf("--all cannot be combined with --cloud, --environment or --region")
+		}
+	} else {
+		for _, item := range []struct {
+			flag  string
+			value string
+		}{
+			{flag: "cloud", value: o.Cloud},
+			{flag: "environment", value: o.Environment},
+			{flag: "region", value: o.Region},
+		} {
+			if item.value == "" {
+				return nil, fmt.Errorf("the %s must be provided with --%s, or every context queried with --all", item.flag, item.flag)
+			}
+		}
+	}
+	switch o.Format {
+	case formatText, formatYAML, formatJSON:
+	default:
+		return nil, fmt.Errorf("invalid format %q, expected one of %s, %s or %s", o.Format, formatText, formatYAML, formatJSON)
+	}
+
+	return &ValidatedOptions{
+		validatedOptions: &validatedOptions{
+			RawOptions: o,
+		},
+	}, nil
+}
+
+func (o *ValidatedOptions) Complete() (*Options, error) {
+	provider, err := config.NewConfigProvider(o.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Options{
+		completedOptions: &completedOptions{
+			Provider:             provider,
+			Cloud:                o.Cloud,
+			Environment:          o.Environment,
+			Region:               o.Region,
+			All:                  o.All,
+			Path:                 o.Path,
+			Provenance:           o.Provenance,
+			Overrides:            o.Overrides,
+			Format:               o.Format,
+			ShowSensitive:        o.ShowSensitive,
+			SensitiveKeyPatterns: o.SensitiveKeyPatterns,
+			Out:                  os.Stdout,
+		},
+	}, nil
+}
+
+// valueReport describes the value at the path in one context.
+type valueReport struct {
+	Context string `json:"context"`
+	// Set is false when the context has no value at the path.
+	Set   bool `json:"set"`
+	Value any  `json:"value,omitempty"`
+	// Provenance lists the levels of overrides which set the value, in the order they are applied.
+	Provenance []provenanceLevel `json:"provenance,omitempty"`
+	// OverridingRegions lists the regions of the cloud and environment whose overrides set the value, when asked for.
+	OverridingRegions []string `json:"overridingRegions,omitzero"`
+}
+
+type provenanceLevel struct {
+	Level string `json:"level"`
+	Value any    `json:"value"`
+}
+
+// Get resolves the value at the path for one context or every context and prints it, along with its provenance and the
+// regions overriding it if requested. Sensitive values are redacted unless they are asked for.
+func (opts *Options) Get(ctx context.Context) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	var contexts []config.ResolvedContext
+	if opts.All {
+		all, err := config.ResolveAllContexts(opts.Provider)
+		if err != nil {
+			return err
+		}
+		contexts = all
+	} else {
+		resolved, err := config.ResolveContext(opts.Provider, opts.Cloud, opts.Environment, opts.Region)
+		if err != nil {
+			return fmt.Errorf("%s/%s/%s: %w", opts.Cloud, opts.Environment, opts.Region, err)
+		}
+		contexts = append(contexts, resolved)
+	}
+
+	reports := make([]valueReport, 0, len(contexts))
+	var set int
+	for _, resolved := range contexts {
+		report, err := opts.report(resolved)
+		if err != nil {
+			return fmt.Errorf("%s: %w", resolved.String(), err)
+		}
+		if report.Set {
+			set++
+		}
+		reports = append(reports, report)
+	}
+	if set == 0 {
+		if opts.All {
+			return fmt.Errorf("%s is not set in any context", opts.Path)
+		}
+		return fmt.Errorf("%s is not set in %s", opts.Path, contexts[0].String())
+	}
+
+	if err := opts.write(reports); err != nil {
+		return fmt.Errorf("failed to write value: %w", err)
+	}
+	logger.Info("Queried configuration value.", "path", opts.Path, "contexts", len(contexts), "set", set)
+	return nil
+}
+
+func (opts *Options) report(resolved config.ResolvedContext) (valueReport, error) {
+	sensitivity, err := config.SensitivityFor(resolved.Resolver, opts.SensitiveKeyPatterns)
+	if err != nil {
+		return valueReport{}, err
+	}
+	if opts.ShowSensitive {
+		sensitivity = nil
+	}
+
+	report := valueReport{Context: resolved.String()}
+	value, err := resolved.Configuration.GetByPath(opts.Path)
+	var missingKeyErr *types.MissingKeyError
+	switch {
+	case errors.As(err, &missingKeyErr):
+		return report, nil
+	case err != nil:
+		return valueReport{}, err
+	}
+	report.Set = true
+	report.Value = sensitivity.RedactAt(opts.Path, value)
+
+	if opts.Provenance {
+		provenance, err := resolved.Resolver.ValueProvenance(resolved.Region, opts.Path)
+		if err != nil {
+			return valueReport{}, fmt.Errorf("failed to determine provenance: %w", err)
+		}
+		for _, level := range []struct {
+			name  string
+			value any
+			set   bool
+		}{
+			{name: "default", value: provenance.Default, set: provenance.DefaultSet},
+			{name: "cloud", value: provenance.Cloud, set: provenance.CloudSet},
+			{name: "environment", value: provenance.Environment, set: provenance.EnvironmentSet},
+			{name: "region", value: provenance.Region, set: provenance.RegionSet},
+			{name: "stamp", value: provenance.Stamp, set: provenance.StampSet},
+		} {
+			if level.set {
+				report.Provenance = append(report.Provenance, provenanceLevel{Level: level.name, Value: sensitivity.RedactAt(opts.Path, level.value)})
+			}
+		}
+	}
+
+	if opts.Overrides {
+		report.OverridingRegions, err = config.OverridingRegions(resolved.Resolver, opts.Path)
+		if err != nil {
+			return valueReport{}, fmt.Errorf("failed to find overriding regions: %w", err)
+		}
+	}
+	return report, nil
+}
+
+func (opts *Options) write(reports []valueReport) error {
+	switch opts.Format {
+	case formatJSON:
+		encoder := json.NewEncoder(opts.Out)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(reports)
+	case formatYAML:
+		encoded, err := yaml.Marshal(reports)
+		if err != nil {
+			return err
+		}
+		_, err = opts.Out.Write(encoded)
+		return err
+	}
+
+	var out strings.Builder
+	for _, report := range reports {
+		if !report.Set {
+			fmt.Fprintf(&out, "%s: <not set>\n", report.Context)
+			continue
+		}
+		value, err := formatValue(report.Value)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&out, "%s: %s\n", report.Context, value)
+		for _, level := range report.Provenance {
+			value, err := formatValue(level.Value)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(&out, "  %s: %s\n", level.Level, value)
+		}
+		if opts.Overrides {
+			regions := strings.Join(report.OverridingRegions, ", ")
+			if regions == "" {
+				regions = "none"
+			}
+			fmt.Fprintf(&out, "  overridden in regions: %s\n", regions)
+		}
+	}
+	_, err := fmt.Fprint(opts.Out, out.String())
+	return err
+}
+
+// formatValue prints strings as they are and any other value as compact JSON.
+func formatValue(value any) (string, error) {
+	if text, ok := value.(string); ok {
+		return text, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode value: %w", err)
+	}
+	return string(encoded), nil
+}
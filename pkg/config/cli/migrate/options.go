@@ -0,0 +1,117 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/cobra"
+
+	"github.com/Azure/ARO-Tools/pkg/config"
+)
+
+func DefaultOptions() *RawOptions {
+	return &RawOptions{}
+}
+
+func BindOptions(opts *RawOptions, cmd *cobra.Command) error {
+	cmd.Flags().StringVar(&opts.InputPath, "input", opts.InputPath, "Path to the configuration file to migrate.")
+	cmd.Flags().StringVar(&opts.OutputPath, "output", opts.OutputPath, "Path to write the migrated configuration to (defaults to input file).")
+
+	for _, flag := range []string{
+		"input",
+		"output",
+	} {
+		if err := cmd.MarkFlagFilename(flag); err != nil {
+			return fmt.Errorf("failed to mark flag %q as a file: %w", flag, err)
+		}
+	}
+	return nil
+}
+
+// RawOptions holds input values.
+type RawOptions struct {
+	InputPath  string
+	OutputPath string
+}
+
+// validatedOptions is a private wrapper that enforces a call of Validate() before Complete() can be invoked.
+type validatedOptions struct {
+	*RawOptions
+}
+
+type ValidatedOptions struct {
+	// Embed a private pointer that cannot be instantiated outside of this package.
+	*validatedOptions
+}
+
+// completedOptions is a private wrapper that enforces a call of Complete() before migration can be invoked.
+type completedOptions struct {
+	Raw        []byte
+	OutputPath string
+}
+
+type Options struct {
+	// Embed a private pointer that cannot be instantiated outside of this package.
+	*completedOptions
+}
+
+func (o *RawOptions) Validate() (*ValidatedOptions, error) {
+	if o.InputPath == "" {
+		return nil, fmt.Errorf("the configuration file to migrate must be provided with --input")
+	}
+
+	if o.OutputPath == "" {
+		o.OutputPath = o.InputPath
+	}
+
+	return &ValidatedOptions{
+		validatedOptions: &validatedOptions{
+			RawOptions: o,
+		},
+	}, nil
+}
+
+func (o *ValidatedOptions) Complete() (*Options, error) {
+	raw, err := os.ReadFile(o.InputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration file %s: %w", o.InputPath, err)
+	}
+
+	return &Options{
+		completedOptions: &completedOptions{
+			Raw:        raw,
+			OutputPath: o.OutputPath,
+		},
+	}, nil
+}
+
+func (opts *Options) Migrate(ctx context.Context) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	migrated, version, err := config.MigrateConfiguration(opts.Raw)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(opts.OutputPath, migrated, 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", opts.OutputPath, err)
+	}
+	logger.Info("Migrated configuration.", "output", opts.OutputPath, "metaSchema", version)
+	return nil
+}
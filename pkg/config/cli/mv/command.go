@@ -0,0 +1,56 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mv
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCommand() (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:           "mv old.path new.path",
+		Short:         "Rename a configuration key in the configuration, its schema, and every pipeline and template that references it.",
+		Args:          cobra.ExactArgs(2),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	opts := DefaultOptions()
+	if err := BindOptions(opts, cmd); err != nil {
+		return nil, fmt.Errorf("failed to bind options: %w", err)
+	}
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer cancel()
+
+		opts.From, opts.To = args[0], args[1]
+		validated, err := opts.Validate()
+		if err != nil {
+			return err
+		}
+		completed, err := validated.Complete()
+		if err != nil {
+			return err
+		}
+		completed.Out = cmd.OutOrStdout()
+		return completed.Move(ctx)
+	}
+
+	return cmd, nil
+}
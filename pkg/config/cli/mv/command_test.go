@@ -0,0 +1,74 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mv
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRun(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"config.yaml", "config.schema.json"} {
+		raw, err := os.ReadFile(filepath.Join("..", "..", "testdata", "rename", file))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), raw, 0644))
+	}
+	configPath := filepath.Join(dir, "config.yaml")
+	original, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+
+	cmd, err := NewCommand()
+	require.NoError(t, err)
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--config", configPath, "--dry-run", "svc.image.digest", "images.svc.digest"})
+	require.NoError(t, cmd.Execute())
+
+	require.Contains(t, out.String(), "--- "+configPath)
+	require.Contains(t, out.String(), "+  images:\n+    svc:\n       digest: sha256:default")
+	require.Contains(t, out.String(), "--- "+filepath.Join(dir, "config.schema.json"))
+
+	unchanged, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	require.Equal(t, string(original), string(unchanged), "a dry run must not write files")
+}
+
+func TestDryRunRedactsSensitiveValues(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"config.yaml", "config.schema.json"} {
+		raw, err := os.ReadFile(filepath.Join("..", "..", "testdata", "sensitivity", file))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), raw, 0644))
+	}
+
+	cmd, err := NewCommand()
+	require.NoError(t, err)
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--config", filepath.Join(dir, "config.yaml"), "--dry-run", "geneva.certificate", "geneva.cert"})
+	require.NoError(t, cmd.Execute())
+
+	// the schema marks geneva.certificate as sensitive, and the values stay sensitive under their new name
+	require.Contains(t, out.String(), "-    certificate: <redacted>")
+	require.Contains(t, out.String(), "+    cert: <redacted>")
+	for _, value := range []string{"default-certificate", "int-certificate", "default-password"} {
+		require.NotContains(t, out.String(), value)
+	}
+}
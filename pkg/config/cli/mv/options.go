@@ -0,0 +1,268 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/go-logr/logr"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+
+	"github.com/Azure/ARO-Tools/pkg/config"
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+	"github.com/Azure/ARO-Tools/pkg/config/usage"
+)
+
+func DefaultOptions() *RawOptions {
+	return &RawOptions{
+		SensitiveKeyPatterns: types.DefaultSensitiveKeyPatterns,
+	}
+}
+
+func BindOptions(opts *RawOptions, cmd *cobra.Command) error {
+	cmd.Flags().StringVar(&opts.ConfigPath, "config", opts.ConfigPath, "Path to the configuration file holding the key.")
+	cmd.Flags().StringVar(&opts.TopologyPath, "topology", opts.TopologyPath, "Path to the topology file listing the pipelines whose references to rewrite.")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", opts.DryRun, "Print a diff of the changes instead of writing them.")
+	cmd.Flags().StringSliceVar(&opts.SensitiveKeyPatterns, "sensitive-key-pattern", opts.SensitiveKeyPatterns, "Regular expression for the names of keys whose values are redacted from the diff.")
+
+	for _, flag := range []string{
+		"config",
+		"topology",
+	} {
+		if err := cmd.MarkFlagFilename(flag); err != nil {
+			return fmt.Errorf("failed to mark flag %q as a file: %w", flag, err)
+		}
+	}
+	return nil
+}
+
+// RawOptions holds input values.
+type RawOptions struct {
+	ConfigPath   string
+	TopologyPath string
+	DryRun       bool
+
+	SensitiveKeyPatterns []string
+
+	From, To string
+}
+
+// validatedOptions is a private wrapper that enforces a call of Validate() before Complete() can be invoked.
+type validatedOptions struct {
+	*RawOptions
+}
+
+type ValidatedOptions struct {
+	// Embed a private pointer that cannot be instantiated outside of this package.
+	*validatedOptions
+}
+
+// completedOptions is a private wrapper that enforces a call of Complete() before the rename can be invoked.
+type completedOptions struct {
+	ConfigPath string
+	Config     []byte
+	// SchemaPath is empty when the configuration has no schema file to rewrite.
+	SchemaPath string
+	Schema     []byte
+	// Index is nil when no topology is provided.
+	Index *usage.Index
+	// Contexts holds every context resolved before the rename.
+	Contexts []config.ResolvedContext
+
+	From, To string
+	DryRun   bool
+	// Sensitivity redacts values assigned to sensitive keys, or to properties the schema marks sensitive, in diffs.
+	Sensitivity *types.Sensitivity
+	Out         io.Writer
+}
+
+type Options struct {
+	// Embed a private pointer that cannot be instantiated outside of this package.
+	*completedOptions
+}
+
+func (o *RawOptions) Validate() (*ValidatedOptions, error) {
+	if o.ConfigPath == "" {
+		return nil, fmt.Errorf("the configuration file must be provided with --config")
+	}
+	if o.From == "" || o.To == "" {
+		return nil, fmt.Errorf("the old and new paths must be provided")
+	}
+
+	return &ValidatedOptions{
+		validatedOptions: &validatedOptions{
+			RawOptions: o,
+		},
+	}, nil
+}
+
+func (o *ValidatedOptions) Complete() (*Options, error) {
+	raw, err := os.ReadFile(o.ConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration: %w", err)
+	}
+	provider, err := config.NewConfigProvider(o.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	contexts, err := config.ResolveAllContexts(provider)
+	if err != nil {
+		return nil, err
+	}
+	if len(contexts) == 0 {
+		return nil, fmt.Errorf("configuration %s has no contexts to verify the rename with", o.ConfigPath)
+	}
+
+	// the provider is loaded from disk, so the schema path is one on disk, where the renamed schema is written
+	schemaPath, err := contexts[0].Resolver.SchemaPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine schema path: %w", err)
+	}
+	schema, err := os.ReadFile(schemaPath)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		schemaPath, schema = "", nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	// without a schema, only the names of keys mark values as sensitive
+	sensitivity, err := types.NewSensitivity(nil, o.SensitiveKeyPatterns)
+	if schema != nil {
+		sensitivity, err = config.SensitivityFor(contexts[0].Resolver, o.SensitiveKeyPatterns)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if sensitivity.IsSensitive(o.From) {
+		// the moved values are just as sensitive under their new name
+		if sensitivity, err = sensitivity.WithPaths(o.To); err != nil {
+			return nil, err
+		}
+	}
+
+	var index *usage.Index
+	if o.TopologyPath != "" {
+		index, err = usage.IndexTopology(o.TopologyPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Options{
+		completedOptions: &completedOptions{
+			ConfigPath:  o.ConfigPath,
+			Config:      raw,
+			SchemaPath:  schemaPath,
+			Schema:      schema,
+			Index:       index,
+			Contexts:    contexts,
+			From:        o.From,
+			To:          o.To,
+			DryRun:      o.DryRun,
+			Sensitivity: sensitivity,
+			Out:         os.Stdout,
+		},
+	}, nil
+}
+
+// Move renames the key in every file, verifies that every context resolves identically afterward and either writes
+// the files or, for a dry run, prints a diff of them in which values assigned to sensitive keys, or to properties the
+// schema marks sensitive, are redacted.
+func (opts *Options) Move(ctx context.Context) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	changes := map[string][]byte{}
+	renamedConfig, err := config.RenameConfigurationKey(opts.Config, opts.From, opts.To)
+	if err != nil {
+		return fmt.Errorf("failed to rename key in %s: %w", opts.ConfigPath, err)
+	}
+	changes[opts.ConfigPath] = renamedConfig
+
+	var providerOpts []config.ProviderOption
+	if opts.SchemaPath != "" {
+		renamedSchema, err := config.RenameSchemaProperty(opts.Schema, opts.From, opts.To)
+		if err != nil {
+			return fmt.Errorf("failed to rename property in %s: %w", opts.SchemaPath, err)
+		}
+		changes[opts.SchemaPath] = renamedSchema
+		providerOpts = append(providerOpts, config.WithSchemaContent(renamedSchema))
+	}
+
+	if opts.Index != nil {
+		references, err := opts.Index.RenameReferences(opts.From, opts.To)
+		if err != nil {
+			return err
+		}
+		for file, content := range references {
+			changes[file] = content
+		}
+	}
+
+	configDir, err := filepath.Abs(filepath.Dir(opts.ConfigPath))
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path for config file %q: %w", opts.ConfigPath, err)
+	}
+	renamed, err := config.NewConfigProviderFromData(renamedConfig, configDir, providerOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to load renamed configuration: %w", err)
+	}
+	if err := config.VerifyRename(opts.Contexts, renamed, opts.From, opts.To); err != nil {
+		return err
+	}
+	logger.Info("Verified that every context resolves identically after the rename.", "contexts", len(opts.Contexts))
+
+	files := make([]string, 0, len(changes))
+	for file := range changes {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		if opts.DryRun {
+			original, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", file, err)
+			}
+			diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        difflib.SplitLines(string(original)),
+				B:        difflib.SplitLines(string(changes[file])),
+				FromFile: file,
+				ToFile:   file,
+				Context:  3,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to diff %s: %w", file, err)
+			}
+			if _, err := fmt.Fprint(opts.Out, opts.Sensitivity.RedactText(diff)); err != nil {
+				return fmt.Errorf("failed to write diff: %w", err)
+			}
+			continue
+		}
+		if err := os.WriteFile(file, changes[file], 0644); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", file, err)
+		}
+	}
+	logger.Info("Renamed configuration key.", "from", opts.From, "to", opts.To, "files", len(files), "dryRun", opts.DryRun)
+	return nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/cobra"
+
+	"github.com/Azure/ARO-Tools/pkg/config"
+	"github.com/Azure/ARO-Tools/pkg/config/render"
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+func DefaultOptions() *RawOptions {
+	return &RawOptions{
+		SensitiveKeyPatterns: types.DefaultSensitiveKeyPatterns,
+	}
+}
+
+func BindOptions(opts *RawOptions, cmd *cobra.Command) error {
+	cmd.Flags().StringVar(&opts.ConfigPath, "config", opts.ConfigPath, "Path to the configuration file to render with.")
+	cmd.Flags().StringVar(&opts.TopologyPath, "topology", opts.TopologyPath, "Path to the topology file.")
+	cmd.Flags().StringVar(&opts.Entrypoint, "entrypoint", opts.Entrypoint, "Identifier of the entrypoint whose pipelines to render.")
+	cmd.Flags().StringVar(&opts.OutputDir, "output", opts.OutputDir, "Directory to render into. Its contents are replaced, if it was rendered into before.")
+	cmd.Flags().BoolVar(&opts.ShowSensitive, "show-sensitive", opts.ShowSensitive, "Write sensitive values instead of redacting them.")
+	cmd.Flags().StringSliceVar(&opts.SensitiveKeyPatterns, "sensitive-key-pattern", opts.SensitiveKeyPatterns, "Regular expression for the names of keys whose values are redacted, along with properties the schema marks as sensitive.")
+
+	for _, flag := range []string{
+		"config",
+		"topology",
+	} {
+		if err := cmd.MarkFlagFilename(flag); err != nil {
+			return fmt.Errorf("failed to mark flag %q as a file: %w", flag, err)
+		}
+	}
+	if err := cmd.MarkFlagDirname("output"); err != nil {
+		return fmt.Errorf("failed to mark flag %q as a directory: %w", "output", err)
+	}
+	return nil
+}
+
+// RawOptions holds input values.
+type RawOptions struct {
+	ConfigPath           string
+	TopologyPath         string
+	Entrypoint           string
+	OutputDir            string
+	ShowSensitive        bool
+	SensitiveKeyPatterns []string
+}
+
+// validatedOptions is a private wrapper that enforces a call of Validate() before Complete() can be invoked.
+type validatedOptions struct {
+	*RawOptions
+}
+
+type ValidatedOptions struct {
+	// Embed a private pointer that cannot be instantiated outside of this package.
+	*validatedOptions
+}
+
+// completedOptions is a private wrapper that enforces a call of Complete() before the rendering can be invoked.
+type completedOptions struct {
+	TopologyPath         string
+	Entrypoint           string
+	Contexts             []config.ResolvedContext
+	OutputDir            string
+	ShowSensitive        bool
+	SensitiveKeyPatterns []string
+}
+
+type Options struct {
+	// Embed a private pointer that cannot be instantiated outside of this package.
+	*completedOptions
+}
+
+func (o *RawOptions) Validate() (*ValidatedOptions, error) {
+	if o.ConfigPath == "" {
+		return nil, fmt.Errorf("the configuration file must be provided with --config")
+	}
+	if o.TopologyPath == "" {
+		return nil, fmt.Errorf("the topology file must be provided with --topology")
+	}
+	if o.Entrypoint == "" {
+		return nil, fmt.Errorf("the entrypoint must be provided with --entrypoint")
+	}
+	if o.OutputDir == "" {
+		return nil, fmt.Errorf("the output directory must be provided with --output")
+	}
+
+	return &ValidatedOptions{
+		validatedOptions: &validatedOptions{
+			RawOptions: o,
+		},
+	}, nil
+}
+
+func (o *ValidatedOptions) Complete() (*Options, error) {
+	provider, err := config.NewConfigProvider(o.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	contexts, err := config.ResolveAllContexts(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Options{
+		completedOptions: &completedOptions{
+			TopologyPath:         o.TopologyPath,
+			Entrypoint:           o.Entrypoint,
+			Contexts:             contexts,
+			OutputDir:            o.OutputDir,
+			ShowSensitive:        o.ShowSensitive,
+			SensitiveKeyPatterns: o.SensitiveKeyPatterns,
+		},
+	}, nil
+}
+
+// Render writes the files rendered for every context to the output directory, with sensitive values redacted unless
+// they are asked for.
+func (opts *Options) Render(ctx context.Context) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	files, err := render.Render(opts.TopologyPath, opts.Entrypoint, opts.Contexts, opts.SensitiveKeyPatterns, opts.ShowSensitive)
+	if err != nil {
+		return err
+	}
+	if err := render.Write(files, opts.OutputDir); err != nil {
+		return err
+	}
+	logger.Info("Rendered templated files.", "entrypoint", opts.Entrypoint, "contexts", len(opts.Contexts), "files", len(files), "output", opts.OutputDir)
+	return nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unused
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/cobra"
+
+	"github.com/Azure/ARO-Tools/pkg/config"
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+	"github.com/Azure/ARO-Tools/pkg/config/usage"
+)
+
+func DefaultOptions() *RawOptions {
+	return &RawOptions{}
+}
+
+func BindOptions(opts *RawOptions, cmd *cobra.Command) error {
+	cmd.Flags().StringVar(&opts.ConfigPath, "config", opts.ConfigPath, "Path to the configuration file whose keys to check.")
+	cmd.Flags().StringVar(&opts.TopologyPath, "topology", opts.TopologyPath, "Path to the topology file listing the pipelines that reference configuration.")
+	cmd.Flags().BoolVar(&opts.Fail, "fail", opts.Fail, "Exit with an error if any key is unused.")
+
+	for _, flag := range []string{
+		"config",
+		"topology",
+	} {
+		if err := cmd.MarkFlagFilename(flag); err != nil {
+			return fmt.Errorf("failed to mark flag %q as a file: %w", flag, err)
+		}
+	}
+	return nil
+}
+
+// RawOptions holds input values.
+type RawOptions struct {
+	ConfigPath   string
+	TopologyPath string
+	Fail         bool
+}
+
+// validatedOptions is a private wrapper that enforces a call of Validate() before Complete() can be invoked.
+type validatedOptions struct {
+	*RawOptions
+}
+
+type ValidatedOptions struct {
+	// Embed a private pointer that cannot be instantiated outside of this package.
+	*validatedOptions
+}
+
+// completedOptions is a private wrapper that enforces a call of Complete() before the report can be invoked.
+type completedOptions struct {
+	Provider config.ConfigProvider
+	Index    *usage.Index
+	Fail     bool
+	Out      io.Writer
+}
+
+type Options struct {
+	// Embed a private pointer that cannot be instantiated outside of this package.
+	*completedOptions
+}
+
+func (o *RawOptions) Validate() (*ValidatedOptions, error) {
+	if o.ConfigPath == "" {
+		return nil, fmt.Errorf("the configuration file must be provided with --config")
+	}
+	if o.TopologyPath == "" {
+		return nil, fmt.Errorf("the topology file must be provided with --topology")
+	}
+
+	return &ValidatedOptions{
+		validatedOptions: &validatedOptions{
+			RawOptions: o,
+		},
+	}, nil
+}
+
+func (o *ValidatedOptions) Complete() (*Options, error) {
+	provider, err := config.NewConfigProvider(o.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	index, err := usage.IndexTopology(o.TopologyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Options{
+		completedOptions: &completedOptions{
+			Provider: provider,
+			Index:    index,
+			Fail:     o.Fail,
+			Out:      os.Stdout,
+		},
+	}, nil
+}
+
+// Report prints every key that is defined for any context but never referenced, one per line.
+func (opts *Options) Report(ctx context.Context) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	contexts, err := config.ResolveAllContexts(opts.Provider)
+	if err != nil {
+		return err
+	}
+	configs := make([]types.Configuration, 0, len(contexts))
+	for _, resolved := range contexts {
+		configs = append(configs, resolved.Configuration)
+	}
+
+	unused := opts.Index.Unused(configs...)
+	for _, key := range unused {
+		if _, err := fmt.Fprintln(opts.Out, key); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+	}
+	logger.Info("Checked configuration keys against references.", "contexts", len(contexts), "referenced", len(opts.Index.Paths()), "unused", len(unused))
+
+	if opts.Fail && len(unused) > 0 {
+		return fmt.Errorf("%d configuration keys are not referenced", len(unused))
+	}
+	return nil
+}
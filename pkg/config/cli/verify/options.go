@@ -0,0 +1,144 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/cobra"
+
+	"github.com/Azure/ARO-Tools/pkg/config"
+)
+
+func DefaultOptions() *RawOptions {
+	return &RawOptions{}
+}
+
+func BindOptions(opts *RawOptions, cmd *cobra.Command) error {
+	cmd.Flags().StringVar(&opts.ConfigPath, "config", opts.ConfigPath, "Path to the configuration file to verify.")
+	cmd.Flags().StringVar(&opts.InvariantsPath, "invariants", opts.InvariantsPath, "Path to a file of CEL invariants to check against every resolved configuration.")
+
+	for _, flag := range []string{
+		"config",
+		"invariants",
+	} {
+		if err := cmd.MarkFlagFilename(flag); err != nil {
+			return fmt.Errorf("failed to mark flag %q as a file: %w", flag, err)
+		}
+	}
+	return nil
+}
+
+// RawOptions holds input values.
+type RawOptions struct {
+	ConfigPath     string
+	InvariantsPath string
+}
+
+// validatedOptions is a private wrapper that enforces a call of Validate() before Complete() can be invoked.
+type validatedOptions struct {
+	*RawOptions
+}
+
+type ValidatedOptions struct {
+	// Embed a private pointer that cannot be instantiated outside of this package.
+	*validatedOptions
+}
+
+// completedOptions is a private wrapper that enforces a call of Complete() before verification can be invoked.
+type completedOptions struct {
+	ConfigPath string
+	// Invariants is nil when no invariants are to be checked.
+	Invariants *config.InvariantChecker
+}
+
+type Options struct {
+	// Embed a private pointer that cannot be instantiated outside of this package.
+	*completedOptions
+}
+
+func (o *RawOptions) Validate() (*ValidatedOptions, error) {
+	if o.ConfigPath == "" {
+		return nil, fmt.Errorf("the configuration file to verify must be provided with --config")
+	}
+
+	return &ValidatedOptions{
+		validatedOptions: &validatedOptions{
+			RawOptions: o,
+		},
+	}, nil
+}
+
+func (o *ValidatedOptions) Complete() (*Options, error) {
+	var checker *config.InvariantChecker
+	if o.InvariantsPath != "" {
+		invariants, err := config.LoadInvariants(o.InvariantsPath)
+		if err != nil {
+			return nil, err
+		}
+		checker, err = config.NewInvariantChecker(invariants)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile invariants from %s: %w", o.InvariantsPath, err)
+		}
+	}
+
+	return &Options{
+		completedOptions: &completedOptions{
+			ConfigPath: o.ConfigPath,
+			Invariants: checker,
+		},
+	}, nil
+}
+
+func (opts *Options) Verify(ctx context.Context) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	provider, err := config.NewConfigProvider(opts.ConfigPath, config.WithTemplateVerification())
+	if err != nil {
+		return err
+	}
+	logger.Info("Verified configuration template for every Ev2 context.", "config", opts.ConfigPath)
+
+	contexts, err := config.ResolveAllContexts(provider)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, ctx := range contexts {
+		if err := ctx.Resolver.ValidateSchema(ctx.Configuration); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ctx, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	logger.Info("Validated configuration schema for every context.", "contexts", len(contexts))
+
+	if err := config.CheckUniqueness(contexts); err != nil {
+		return err
+	}
+	logger.Info("Checked uniqueness of configuration values across contexts.")
+
+	if opts.Invariants != nil {
+		if err := opts.Invariants.CheckContexts(contexts); err != nil {
+			return err
+		}
+		logger.Info("Checked configuration invariants for every context.")
+	}
+	return nil
+}
@@ -0,0 +1,449 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package codegen generates Go types from a service configuration schema, so that consumers of a resolved
+// configuration can work with structs instead of maps and string paths.
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Options configures the generated code.
+type Options struct {
+	// Package is the name of the package holding the generated code.
+	Package string
+	// TypeName is the name of the struct generated for the whole configuration; defaults to Configuration.
+	TypeName string
+}
+
+// Generate emits Go source for the configuration schema: a struct for every object with declared properties, with
+// JSON tags and doc comments from the schema's descriptions, a string type with constants for every string enum, and
+// a Load function that decodes a resolved configuration into the generated struct. Local references are followed and
+// each referenced definition is generated once. Schemas that can't be represented precisely, like a oneOf, become any.
+func Generate(schema []byte, opts Options) ([]byte, error) {
+	if opts.Package == "" {
+		return nil, fmt.Errorf("a package name is required")
+	}
+	if opts.TypeName == "" {
+		opts.TypeName = "Configuration"
+	}
+
+	document := map[string]any{}
+	if err := json.Unmarshal(schema, &document); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schema: %w", err)
+	}
+
+	g := &generator{
+		document:    document,
+		names:       map[string]bool{"Load": true},
+		definitions: map[string]string{},
+	}
+	root, err := g.resolve(document, "")
+	if err != nil {
+		return nil, err
+	}
+	if !isObject(root) && schemaType(root) != "object" {
+		return nil, fmt.Errorf("the schema must describe an object")
+	}
+	g.names[opts.TypeName] = true
+	if err := g.object(opts.TypeName, "", root); err != nil {
+		return nil, err
+	}
+
+	out := &bytes.Buffer{}
+	fmt.Fprintf(out, "// Code generated from the service configuration schema. DO NOT EDIT.\n\n")
+	fmt.Fprintf(out, "package %s\n\n", opts.Package)
+	fmt.Fprintf(out, "import (\n\t\"github.com/Azure/ARO-Tools/pkg/config/types\"\n)\n\n")
+	fmt.Fprintf(out, "// Load decodes a resolved configuration into a %[1]s. Values that don't match the generated types are\n", opts.TypeName)
+	fmt.Fprintf(out, "// reported with a *types.DecodeError holding the path to them.\n")
+	fmt.Fprintf(out, "func Load(cfg types.Configuration) (*%[1]s, error) {\n\tout := &%[1]s{}\n", opts.TypeName)
+	fmt.Fprintf(out, "\tif err := types.Decode(cfg, out); err != nil {\n\t\treturn nil, err\n\t}\n\treturn out, nil\n}\n")
+	for _, decl := range g.decls {
+		out.WriteString("\n")
+		out.WriteString(decl)
+	}
+
+	formatted, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return formatted, nil
+}
+
+type generator struct {
+	document map[string]any
+	// names records the type names in use
+	names map[string]bool
+	// definitions maps local references to the names of the types generated for them
+	definitions map[string]string
+	decls       []string
+}
+
+// resolve follows a local reference, if the schema is one.
+func (g *generator) resolve(schema map[string]any, path string) (map[string]any, error) {
+	seen := map[string]bool{}
+	for {
+		ref, ok := schema["$ref"].(string)
+		if !ok {
+			return schema, nil
+		}
+		if seen[ref] {
+			return nil, fmt.Errorf("%s: circular reference %s", describe(path), ref)
+		}
+		seen[ref] = true
+		target, err := g.lookup(ref)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", describe(path), err)
+		}
+		schema = target
+	}
+}
+
+// lookup resolves a JSON pointer into the document.
+func (g *generator) lookup(ref string) (map[string]any, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("only local references are supported, not %s", ref)
+	}
+	var current any = g.document
+	for _, token := range strings.Split(strings.TrimPrefix(strings.TrimPrefix(ref, "#"), "/"), "/") {
+		if token == "" {
+			continue
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("reference %s does not resolve", ref)
+		}
+		if current, ok = object[token]; !ok {
+			return nil, fmt.Errorf("reference %s does not resolve", ref)
+		}
+	}
+	target, ok := current.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("reference %s does not resolve to a schema", ref)
+	}
+	return target, nil
+}
+
+// typeFor returns the Go type for the value at the path, declaring any types needed for it.
+func (g *generator) typeFor(name, path string, schema map[string]any) (string, error) {
+	if ref, ok := schema["$ref"].(string); ok {
+		if declared, ok := g.definitions[ref]; ok {
+			return declared, nil
+		}
+		target, err := g.resolve(schema, path)
+		if err != nil {
+			return "", err
+		}
+		if isObject(target) || isStringEnum(target) {
+			// name the type for the definition, so every use of it shares the type
+			definition := ref[strings.LastIndex(ref, "/")+1:]
+			declared := g.name(goName(definition))
+			g.definitions[ref] = declared
+			if isObject(target) {
+				return declared, g.object(declared, ref, target)
+			}
+			return declared, g.enum(declared, ref, target)
+		}
+		return g.typeFor(name, path, target)
+	}
+
+	switch {
+	case isObject(schema):
+		declared := g.name(name)
+		return declared, g.object(declared, path, schema)
+	case isStringEnum(schema):
+		declared := g.name(name)
+		return declared, g.enum(declared, path, schema)
+	}
+
+	for _, keyword := range []string{"oneOf", "anyOf"} {
+		if _, ok := schema[keyword]; ok {
+			return "any", nil
+		}
+	}
+
+	switch schemaType(schema) {
+	case "string":
+		return "string", nil
+	case "integer":
+		return "int", nil
+	case "number":
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		items, ok := schema["items"].(map[string]any)
+		if !ok {
+			return "[]any", nil
+		}
+		item, err := g.typeFor(name+"Item", path+"[]", items)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + item, nil
+	case "object":
+		if additional, ok := schema["additionalProperties"].(map[string]any); ok {
+			value, err := g.typeFor(name+"Value", path+".*", additional)
+			if err != nil {
+				return "", err
+			}
+			return "map[string]" + value, nil
+		}
+		return "map[string]any", nil
+	default:
+		return "any", nil
+	}
+}
+
+// object declares a struct for an object schema.
+func (g *generator) object(name, path string, schema map[string]any) error {
+	properties, _ := schema["properties"].(map[string]any)
+	required := map[string]bool{}
+	if list, ok := schema["required"].([]any); ok {
+		for _, item := range list {
+			if property, ok := item.(string); ok {
+				required[property] = true
+			}
+		}
+	}
+
+	// reserve the slot for this declaration so that types appear in the order they are reached
+	index := len(g.decls)
+	g.decls = append(g.decls, "")
+
+	decl := &bytes.Buffer{}
+	writeDoc(decl, fmt.Sprintf("%s holds %s.", name, describe(path)), schema)
+	fmt.Fprintf(decl, "type %s struct {\n", name)
+	fields := map[string]bool{}
+	written := 0
+	for _, property := range sortedKeys(properties) {
+		propertySchema, ok := properties[property].(map[string]any)
+		if !ok {
+			continue
+		}
+		field := unique(goName(property), fields)
+
+		childPath := property
+		if path != "" {
+			childPath = path + "." + property
+		}
+		fieldType, err := g.typeFor(name+field, childPath, propertySchema)
+		if err != nil {
+			return err
+		}
+		tag := property
+		if !required[property] {
+			tag += ",omitempty"
+			if resolved, err := g.resolve(propertySchema, childPath); err == nil && isObject(resolved) {
+				fieldType = "*" + fieldType
+			}
+		}
+		// separate documented fields from the ones before them
+		doc := &bytes.Buffer{}
+		writeFieldDoc(doc, propertySchema)
+		if doc.Len() > 0 && written > 0 {
+			decl.WriteString("\n")
+		}
+		decl.Write(doc.Bytes())
+		written++
+		fmt.Fprintf(decl, "\t%s %s `json:%q`\n", field, fieldType, tag)
+	}
+	decl.WriteString("}\n")
+	g.decls[index] = decl.String()
+	return nil
+}
+
+// enum declares a string type with a constant for every value of a string enum.
+func (g *generator) enum(name, path string, schema map[string]any) error {
+	decl := &bytes.Buffer{}
+	writeDoc(decl, fmt.Sprintf("%s enumerates the values allowed for %s.", name, describe(path)), schema)
+	fmt.Fprintf(decl, "type %s string\n\nconst (\n", name)
+	for _, value := range schema["enum"].([]any) {
+		// constants share the package namespace with the types, so they are named like them
+		constant := g.name(name + goName(value.(string)))
+		fmt.Fprintf(decl, "\t%s %s = %q\n", constant, name, value)
+	}
+	decl.WriteString(")\n")
+	g.decls = append(g.decls, decl.String())
+	return nil
+}
+
+// name returns a type name that isn't in use yet, based on the given one.
+func (g *generator) name(base string) string {
+	return unique(base, g.names)
+}
+
+// unique returns a name that isn't in use yet, based on the given one, and records it as used.
+func unique(base string, used map[string]bool) string {
+	name := base
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	used[name] = true
+	return name
+}
+
+func isObject(schema map[string]any) bool {
+	_, ok := schema["properties"].(map[string]any)
+	return ok
+}
+
+func isStringEnum(schema map[string]any) bool {
+	values, ok := schema["enum"].([]any)
+	if !ok || len(values) == 0 {
+		return false
+	}
+	for _, value := range values {
+		if _, ok := value.(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// schemaType returns the type of a schema, ignoring null in a list of types.
+func schemaType(schema map[string]any) string {
+	switch typed := schema["type"].(type) {
+	case string:
+		return typed
+	case []any:
+		var types []string
+		for _, item := range typed {
+			if s, ok := item.(string); ok && s != "null" {
+				types = append(types, s)
+			}
+		}
+		if len(types) == 1 {
+			return types[0]
+		}
+	}
+	return ""
+}
+
+// describe names the configuration at a dot path, or the schema definition for a reference.
+func describe(path string) string {
+	switch {
+	case path == "":
+		return "the configuration"
+	case strings.HasPrefix(path, "#"):
+		return "the definition at " + path
+	default:
+		return "the configuration at " + path
+	}
+}
+
+func writeDoc(out *bytes.Buffer, summary string, schema map[string]any) {
+	fmt.Fprintf(out, "// %s\n", summary)
+	if description, ok := schema["description"].(string); ok && description != "" {
+		out.WriteString("//\n")
+		writeComment(out, "", description)
+	}
+}
+
+func writeFieldDoc(out *bytes.Buffer, schema map[string]any) {
+	var paragraphs []string
+	if description, ok := schema["description"].(string); ok && description != "" {
+		paragraphs = append(paragraphs, description)
+	}
+	if values, ok := schema["enum"].([]any); ok && !isStringEnum(schema) {
+		var allowed []string
+		for _, value := range values {
+			allowed = append(allowed, fmt.Sprintf("%v", value))
+		}
+		paragraphs = append(paragraphs, "Allowed values: "+strings.Join(allowed, ", ")+".")
+	}
+	if deprecated, ok := schema["deprecated"].(bool); ok && deprecated {
+		paragraphs = append(paragraphs, "Deprecated: this key is deprecated in the schema.")
+	}
+	for i, paragraph := range paragraphs {
+		if i > 0 {
+			out.WriteString("\t//\n")
+		}
+		writeComment(out, "\t", paragraph)
+	}
+}
+
+func writeComment(out *bytes.Buffer, indent, text string) {
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		fmt.Fprintf(out, "%s// %s\n", indent, strings.TrimRight(line, " \t"))
+	}
+}
+
+// commonInitialisms are upper-cased in generated names, following Go conventions.
+var commonInitialisms = map[string]string{
+	"acr": "ACR", "aks": "AKS", "api": "API", "cpu": "CPU", "dns": "DNS", "http": "HTTP", "https": "HTTPS", "id": "ID", "ip": "IP",
+	"json": "JSON", "msi": "MSI", "oidc": "OIDC", "rg": "RG", "sku": "SKU", "tls": "TLS", "uri": "URI", "url": "URL",
+	"uuid": "UUID", "vm": "VM",
+}
+
+// goName converts a key into an exported Go identifier, splitting words on punctuation and case changes.
+func goName(key string) string {
+	var words []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, string(current))
+			current = nil
+		}
+	}
+	runes := []rune(key)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])):
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush()
+
+	out := strings.Builder{}
+	for _, word := range words {
+		if initialism, ok := commonInitialisms[strings.ToLower(word)]; ok {
+			out.WriteString(initialism)
+			continue
+		}
+		word := []rune(word)
+		out.WriteRune(unicode.ToUpper(word[0]))
+		out.WriteString(string(word[1:]))
+	}
+	name := out.String()
+	if name == "" {
+		return "Value"
+	}
+	if unicode.IsDigit([]rune(name)[0]) {
+		name = "V" + name
+	}
+	return name
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
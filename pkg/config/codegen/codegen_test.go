@@ -0,0 +1,90 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Azure/ARO-Tools/internal/testutil"
+	"github.com/Azure/ARO-Tools/pkg/config/codegen"
+)
+
+func TestGenerate(t *testing.T) {
+	schema, err := os.ReadFile("testdata/config.schema.json")
+	require.NoError(t, err)
+
+	generated, err := codegen.Generate(schema, codegen.Options{Package: "svcconfig"})
+	require.NoError(t, err)
+	testutil.CompareWithFixture(t, generated, testutil.WithExtension(".go"))
+}
+
+func TestGenerateNameCollisions(t *testing.T) {
+	// A-b-2 and a-b both want AB2 once AB is taken, the enum values all want ConfigurationModeAB, and the type for
+	// modeAB wants the name of the first constant.
+	schema := `{
+  "type": "object",
+  "properties": {
+    "A-b-2": {"type": "string"},
+    "AB": {"type": "string"},
+    "a-b": {"type": "string"},
+    "mode": {"type": "string", "enum": ["a-b", "AB", "a_b"]},
+    "modeAB": {"type": "object", "properties": {"x": {"type": "string"}}}
+  }
+}`
+	generated, err := codegen.Generate([]byte(schema), codegen.Options{Package: "svcconfig"})
+	require.NoError(t, err)
+	testutil.CompareWithFixture(t, generated, testutil.WithExtension(".go"))
+}
+
+func TestGenerateErrors(t *testing.T) {
+	for _, testCase := range []struct {
+		name   string
+		schema string
+		opts   codegen.Options
+		err    string
+	}{
+		{
+			name:   "missing package",
+			schema: `{"type": "object", "properties": {}}`,
+			err:    "a package name is required",
+		},
+		{
+			name:   "not an object",
+			schema: `{"type": "string"}`,
+			opts:   codegen.Options{Package: "svcconfig"},
+			err:    "the schema must describe an object",
+		},
+		{
+			name:   "remote reference",
+			schema: `{"type": "object", "properties": {"image": {"$ref": "https://example.com/image.json"}}}`,
+			opts:   codegen.Options{Package: "svcconfig"},
+			err:    "the configuration at image: only local references are supported",
+		},
+		{
+			name:   "dangling reference",
+			schema: `{"type": "object", "properties": {"image": {"$ref": "#/definitions/image"}}}`,
+			opts:   codegen.Options{Package: "svcconfig"},
+			err:    "the configuration at image: reference #/definitions/image does not resolve",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := codegen.Generate([]byte(testCase.schema), testCase.opts)
+			require.ErrorContains(t, err, testCase.err)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+// Code generated from the service configuration schema. DO NOT EDIT.
+
+package svcconfig
+
+import (
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+// Load decodes a resolved configuration into a Configuration. Values that don't match the generated types are
+// reported with a *types.DecodeError holding the path to them.
+func Load(cfg types.Configuration) (*Configuration, error) {
+	out := &Configuration{}
+	if err := types.Decode(cfg, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Configuration holds the configuration.
+type Configuration struct {
+	Backend  *ConfigurationBackend `json:"backend,omitempty"`
+	Frontend ConfigurationFrontend `json:"frontend"`
+
+	// Resource group holding the regional resources.
+	RegionRG string `json:"regionRG"`
+}
+
+// ConfigurationBackend holds the configuration at backend.
+type ConfigurationBackend struct {
+	// Deprecated: this key is deprecated in the schema.
+	Enabled  bool   `json:"enabled,omitempty"`
+	Image    *Image `json:"image,omitempty"`
+	Settings any    `json:"settings,omitempty"`
+
+	// How TLS is terminated.
+	TLSMode ConfigurationBackendTLSMode `json:"tlsMode,omitempty"`
+
+	// Allowed values: 1, 3.
+	Zones int `json:"zones,omitempty"`
+}
+
+// Image holds the definition at #/definitions/image.
+//
+// An image in a container registry.
+type Image struct {
+	// Digest pinning the image.
+	Digest string `json:"digest,omitempty"`
+
+	// Registry hosting the image.
+	Registry   string `json:"registry"`
+	Repository string `json:"repository"`
+}
+
+// ConfigurationBackendTLSMode enumerates the values allowed for the configuration at backend.tlsMode.
+//
+// How TLS is terminated.
+type ConfigurationBackendTLSMode string
+
+const (
+	ConfigurationBackendTLSModeEdge        ConfigurationBackendTLSMode = "edge"
+	ConfigurationBackendTLSModePassthrough ConfigurationBackendTLSMode = "passthrough"
+)
+
+// ConfigurationFrontend holds the configuration at frontend.
+type ConfigurationFrontend struct {
+	CPU      float64           `json:"cpu,omitempty"`
+	Hosts    []string          `json:"hosts,omitempty"`
+	Image    Image             `json:"image"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	LogLevel LogLevel          `json:"logLevel,omitempty"`
+
+	// Number of replicas to run.
+	Replicas int `json:"replicas"`
+}
+
+// LogLevel enumerates the values allowed for the definition at #/definitions/logLevel.
+type LogLevel string
+
+const (
+	LogLevelDebug LogLevel = "debug"
+	LogLevelInfo  LogLevel = "info"
+	LogLevelWarn  LogLevel = "warn"
+)
@@ -20,14 +20,19 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
+	"sync"
 	"text/template"
 
 	"github.com/santhosh-tekuri/jsonschema/v6"
 
 	"sigs.k8s.io/yaml"
 
+	"github.com/Azure/ARO-Tools/pkg/cmdutils"
 	"github.com/Azure/ARO-Tools/pkg/config/ev2config"
 	"github.com/Azure/ARO-Tools/pkg/config/types"
 
@@ -42,18 +47,73 @@ type ConfigReplacements struct {
 	CloudReplacement       string
 	EnvironmentReplacement string
 
+	RegionFriendlyNameReplacement    string
+	GeographyReplacement             string
+	AvailabilityZoneCountReplacement int
+
 	Ev2Config map[string]interface{}
 }
 
+// NewConfigReplacements creates the replacement values for a context, looking up the Ev2 central configuration and
+// the details of the region from the Ev2 catalog. The dev cloud is not in the catalog, so it is looked up as the public
+// cloud, like everywhere else dev is deployed; the replacements still record dev as the cloud.
+func NewConfigReplacements(cloud, environment, region, stamp string) (*ConfigReplacements, error) {
+	ev2Cloud := cloud
+	if ev2Cloud == string(cmdutils.RolloutCloudDev) {
+		ev2Cloud = string(cmdutils.RolloutCloudPublic)
+	}
+	ev2Cfg, err := ev2config.ResolveConfig(ev2Cloud, region)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve ev2 configuration for %s/%s: %w", cloud, region, err)
+	}
+
+	replacements := &ConfigReplacements{
+		RegionReplacement:      region,
+		StampReplacement:       stamp,
+		CloudReplacement:       cloud,
+		EnvironmentReplacement: environment,
+		Ev2Config:              ev2Cfg,
+	}
+	for key, into := range map[string]*string{
+		"regionShortName":    &replacements.RegionShortReplacement,
+		"regionFriendlyName": &replacements.RegionFriendlyNameReplacement,
+		"geography":          &replacements.GeographyReplacement,
+	} {
+		value, err := ev2Cfg.GetByPath(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find %s for %s/%s: %w", key, cloud, region, err)
+		}
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected %s for %s/%s to be a string, got %T", key, cloud, region, value)
+		}
+		*into = str
+	}
+	zones, err := ev2Cfg.GetByPath("availabilityZoneCount")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find availabilityZoneCount for %s/%s: %w", cloud, region, err)
+	}
+	count, ok := zones.(float64)
+	if !ok {
+		return nil, fmt.Errorf("expected availabilityZoneCount for %s/%s to be a number, got %T", cloud, region, zones)
+	}
+	replacements.AvailabilityZoneCountReplacement = int(count)
+
+	return replacements, nil
+}
+
 // AsMap returns a map[string]interface{} representation of this ConfigReplacement instance
 func (c ConfigReplacements) AsMap() map[string]interface{} {
 	m := map[string]interface{}{
 		"ctx": map[string]interface{}{
-			"region":      c.RegionReplacement,
-			"regionShort": c.RegionShortReplacement,
-			"stamp":       c.StampReplacement,
-			"cloud":       c.CloudReplacement,
-			"environment": c.EnvironmentReplacement,
+			"region":                c.RegionReplacement,
+			"regionShort":           c.RegionShortReplacement,
+			"regionFriendlyName":    c.RegionFriendlyNameReplacement,
+			"geography":             c.GeographyReplacement,
+			"availabilityZoneCount": c.AvailabilityZoneCountReplacement,
+			"stamp":                 c.StampReplacement,
+			"cloud":                 c.CloudReplacement,
+			"environment":           c.EnvironmentReplacement,
 		},
 		"ev2": c.Ev2Config,
 	}
@@ -74,8 +134,13 @@ type ConfigProvider interface {
 type ConfigResolver interface {
 	// ValidateSchema validates a fully resolved configuration created by this provider.
 	ValidateSchema(config types.Configuration) error
-	// SchemaPath returns the absolute path to the JSONSchema file that this config is registered as using.
+	// SchemaPath returns the path to the JSONSchema file that this config is registered as using: an absolute path on
+	// disk or, for providers created with NewConfigProviderFromFS, a path within their filesystem. Use SchemaDocument
+	// to read the schema wherever it is.
 	SchemaPath() (string, error)
+	// SchemaDocument loads the JSONSchema that this config is registered as using as a raw document, for inspection of
+	// annotations like x-unique, or returns nil if the config isn't registered as using one. Callers must not mutate it.
+	SchemaDocument() (map[string]any, error)
 	// GetConfiguration resolves the configuration for the cloud and environment.
 	GetConfiguration() (types.Configuration, error)
 	// GetRegions divulges the regions for which overrides are registered.
@@ -84,10 +149,41 @@ type ConfigResolver interface {
 	GetRegionConfiguration(region string) (types.Configuration, error)
 	// GetRegionOverrides fetches the overrides specific to a region, if any exist.
 	GetRegionOverrides(region string) (types.Configuration, error)
+	// GetRegionConfigurationWithWarnings resolves the configuration for a region in the cloud and environment, and
+	// divulges warnings for every deprecated key that the contributing overrides set.
+	GetRegionConfigurationWithWarnings(region string) (types.Configuration, []DeprecationWarning, error)
 	// ValueProvenance divulges how the value at 'path' is overridden to arrive at the result.
 	ValueProvenance(region, path string) (*Provenance, error)
 }
 
+// ProviderOption configures optional behavior of a ConfigProvider.
+type ProviderOption func(*configProvider)
+
+// WithStrictDeprecations makes resolvers fail to resolve configurations which set keys that the schema marks as
+// deprecated, instead of only divulging warnings.
+func WithStrictDeprecations() ProviderOption {
+	return func(cp *configProvider) {
+		cp.strictDeprecations = true
+	}
+}
+
+// WithSchemaContent provides the service schema in memory, instead of having resolvers load it from the path that the
+// configuration file records in $schema.
+func WithSchemaContent(content []byte) ProviderOption {
+	return func(cp *configProvider) {
+		cp.schemaContent = content
+	}
+}
+
+// WithTemplateVerification makes the provider verify that the configuration template can be processed for every cloud
+// and region in the Ev2 central configuration, in every environment and stamp the configuration declares, before it
+// is loaded. See VerifyTemplate.
+func WithTemplateVerification() ProviderOption {
+	return func(cp *configProvider) {
+		cp.verifyTemplate = true
+	}
+}
+
 // NewConfigProvider creates a configuration provider by knowing the path to the configuration file.
 // Configuration files are not valid YAML - they are text templates that, when provided with the correct set of inputs
 // and run through the Go template engine, become valid YAML. We want to be able to load the whole config file before
@@ -97,7 +193,7 @@ type ConfigResolver interface {
 //
 // We instead run the file through a dummy template step that replaces values with some cloud/env/region and - since
 // this ConfigProvider can't do anything except for divulge the contexts - we can just do the correct templating later.
-func NewConfigProvider(config string) (ConfigProvider, error) {
+func NewConfigProvider(config string, opts ...ProviderOption) (ConfigProvider, error) {
 	raw, err := os.ReadFile(config)
 	if err != nil {
 		return nil, err
@@ -106,45 +202,51 @@ func NewConfigProvider(config string) (ConfigProvider, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get absolute path for config file %q: %w", config, err)
 	}
-	return NewConfigProviderFromData(raw, absPath)
+	return NewConfigProviderFromData(raw, absPath, opts...)
 }
 
 // NewConfigProviderFromData creates a configuration provider from raw configuration data and the reference directory
 // for resolving relative schema paths. The schemaBaseDir is used to turn a relative schema path into an absolute one.
-func NewConfigProviderFromData(raw []byte, schemaBaseDir string) (ConfigProvider, error) {
+func NewConfigProviderFromData(raw []byte, schemaBaseDir string, opts ...ProviderOption) (ConfigProvider, error) {
+	return newConfigProvider(raw, nil, schemaBaseDir, opts...)
+}
+
+// NewConfigProviderFromFS creates a configuration provider from the configuration file at path in the filesystem.
+// The $schema it records is resolved relative to the directory holding the file, and the service schema - along
+// with any schemas it references - is read through the same filesystem, so configuration can be embedded in a
+// binary or provided by a fstest.MapFS.
+func NewConfigProviderFromFS(fsys fs.FS, configPath string, opts ...ProviderOption) (ConfigProvider, error) {
+	raw, err := fs.ReadFile(fsys, configPath)
+	if err != nil {
+		return nil, err
+	}
+	return newConfigProvider(raw, fsys, path.Dir(configPath), opts...)
+}
+
+// newConfigProvider creates a configuration provider, reading the service schema from the filesystem if one is given
+// and from disk otherwise.
+func newConfigProvider(raw []byte, fsys fs.FS, schemaBaseDir string, opts ...ProviderOption) (ConfigProvider, error) {
 	cp := configProvider{
 		raw: raw,
 	}
-
-	ev2Cfg, err := ev2config.ResolveConfig("public", "uksouth")
-	if err != nil {
-		return nil, fmt.Errorf("failed to resolve ev2 configuration: %w", err)
+	for _, opt := range opts {
+		opt(&cp)
 	}
 
-	rawContent, err := PreprocessContent(cp.raw, ConfigReplacements{
-		CloudReplacement:       "public",
-		EnvironmentReplacement: "int",
-		RegionReplacement:      "uksouth",
-		RegionShortReplacement: "ln",
-		StampReplacement:       "1",
-		Ev2Config:              ev2Cfg,
-	}.AsMap())
+	fakeReplacements, err := NewConfigReplacements("public", "int", "uksouth", "1")
 	if err != nil {
 		return nil, err
 	}
 
-	if err := yaml.Unmarshal(rawContent, &cp.withFakeReplacements); err != nil {
+	cp.template, err = parseTemplate("", cp.raw)
+	if err != nil {
 		return nil, err
 	}
 
-	schemaPath := cp.withFakeReplacements.Schema
-	if !filepath.IsAbs(cp.withFakeReplacements.Schema) {
-		schemaPath, err = filepath.Abs(filepath.Join(schemaBaseDir, schemaPath))
-		if err != nil {
-			return nil, fmt.Errorf("failed to create absolute path to schema %q: %w", schemaPath, err)
-		}
+	rawContent, err := cp.template.execute(fakeReplacements.AsMap())
+	if err != nil {
+		return nil, err
 	}
-	cp.absoluteSchemaPath = schemaPath
 
 	forValidation := map[string]any{}
 	if err := yaml.Unmarshal(rawContent, &forValidation); err != nil {
@@ -154,13 +256,47 @@ func NewConfigProviderFromData(raw []byte, schemaBaseDir string) (ConfigProvider
 		return nil, err
 	}
 
+	cp.withFakeReplacements, err = loadConfigurationOverrides(rawContent)
+	if err != nil {
+		return nil, err
+	}
+
+	if cp.verifyTemplate {
+		if err := verifyTemplate(cp.template, cp.withFakeReplacements); err != nil {
+			return nil, err
+		}
+	}
+
+	schemaPath := cp.withFakeReplacements.Schema
+	switch {
+	case fsys != nil:
+		// paths in a filesystem are always relative to its root
+		if !path.IsAbs(schemaPath) {
+			schemaPath = path.Join(schemaBaseDir, schemaPath)
+		}
+		schemaPath = strings.TrimPrefix(path.Clean(schemaPath), "/")
+		if !fs.ValidPath(schemaPath) {
+			return nil, fmt.Errorf("schema %q resolves to %q, which is outside of the filesystem", cp.withFakeReplacements.Schema, schemaPath)
+		}
+	case !filepath.IsAbs(schemaPath):
+		schemaPath, err = filepath.Abs(filepath.Join(schemaBaseDir, schemaPath))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create absolute path to schema %q: %w", schemaPath, err)
+		}
+	}
+	cp.schema = &serviceSchema{absolutePath: schemaPath, content: cp.schemaContent, fsys: fsys}
+
 	return &cp, nil
 }
 
 type configProvider struct {
-	absoluteSchemaPath   string
+	schemaContent        []byte
+	schema               *serviceSchema
 	raw                  []byte
+	template             *parsedTemplate
 	withFakeReplacements configurationOverrides
+	strictDeprecations   bool
+	verifyTemplate       bool
 }
 
 // AllContexts returns all clouds, environments and regions in the configuration.
@@ -190,49 +326,79 @@ func (cp *configProvider) GetResolver(configReplacements *ConfigReplacements) (C
 
 	// TODO validate that field names are unique regardless of casing
 	// parse, execute and unmarshal the config file as a template to generate the final config file
-	rawContent, err := PreprocessContent(cp.raw, configReplacements.AsMap())
+	rawContent, err := cp.template.execute(configReplacements.AsMap())
 	if err != nil {
 		return nil, err
 	}
 
-	currentVariableOverrides := configurationOverrides{}
-	if err := yaml.Unmarshal(rawContent, &currentVariableOverrides); err != nil {
+	currentVariableOverrides, err := loadConfigurationOverrides(rawContent)
+	if err != nil {
 		return nil, err
 	}
 	return &configResolver{
 		cloud:              configReplacements.CloudReplacement,
 		environment:        configReplacements.EnvironmentReplacement,
+		stamp:              configReplacements.StampReplacement,
 		cfg:                currentVariableOverrides,
-		absoluteSchemaPath: cp.absoluteSchemaPath,
+		schema:             cp.schema,
+		strictDeprecations: cp.strictDeprecations,
 	}, nil
 }
 
 type configResolver struct {
 	cloud, environment string
+	stamp              string
 	cfg                configurationOverrides
-	absoluteSchemaPath string
+	schema             *serviceSchema
+	strictDeprecations bool
+
+	// merged caches resolved configurations by region; the empty key holds the configuration with no region, which is
+	// the base every region is merged onto. The cache can't be shared between resolvers, even for the same cloud and
+	// environment, as each resolver processes the template with its own context, so every layer may differ.
+	mergedLock sync.Mutex
+	merged     map[string]types.Configuration
 }
 
 func (cr *configResolver) ValidateSchema(config types.Configuration) error {
-	loader := jsonschema.SchemeURLLoader{
-		"file": jsonschema.FileLoader{},
-	}
-	c := jsonschema.NewCompiler()
-	c.UseLoader(loader)
-	sch, err := c.Compile(cr.absoluteSchemaPath)
+	sch, err := cr.schema.compile()
 	if err != nil {
-		return fmt.Errorf("failed to compile schema: %v", err)
+		return err
 	}
 
 	err = sch.Validate(map[string]any(config))
 	if err != nil {
 		return fmt.Errorf("failed to validate schema: %v", err)
 	}
+
+	document, err := cr.schemaDocument()
+	if err != nil {
+		return fmt.Errorf("failed to load schema: %w", err)
+	}
+	if err := checkResourceNames(document, config); err != nil {
+		return fmt.Errorf("failed to validate schema: %w", err)
+	}
 	return nil
 }
 
+// schemaDocument loads the service schema as a raw document, for inspection of annotations.
+func (cr *configResolver) schemaDocument() (map[string]any, error) {
+	return cr.schema.rawDocument()
+}
+
+func (cr *configResolver) SchemaDocument() (map[string]any, error) {
+	if !cr.hasSchema() {
+		return nil, nil
+	}
+	return cr.schemaDocument()
+}
+
+// hasSchema determines if the configuration is registered as using a service schema.
+func (cr *configResolver) hasSchema() bool {
+	return cr.cfg.Schema != "" || cr.schema.content != nil
+}
+
 func (cr *configResolver) SchemaPath() (string, error) {
-	return cr.absoluteSchemaPath, nil
+	return cr.schema.absolutePath, nil
 }
 
 func (cr *configResolver) GetRegions() ([]string, error) {
@@ -254,44 +420,182 @@ func (cr *configResolver) GetRegions() ([]string, error) {
 
 // GetRegionConfiguration merges values to resolve the configuration for a region.
 func (cr *configResolver) GetRegionConfiguration(region string) (types.Configuration, error) {
-	cfg := cr.cfg.Defaults
-	cloudCfg, hasCloud := cr.cfg.Overrides[cr.cloud]
-	if !hasCloud {
-		return nil, fmt.Errorf("the cloud %s is not found in the config", cr.cloud)
+	return cr.resolve(region, true)
+}
+
+// GetRegionConfigurationWithWarnings merges values to resolve the configuration for a region, recording the use of
+// deprecated keys along the way.
+func (cr *configResolver) GetRegionConfigurationWithWarnings(region string) (types.Configuration, []DeprecationWarning, error) {
+	layers, err := cr.layers(region, true)
+	if err != nil {
+		return nil, nil, err
 	}
-	cfg = types.MergeConfiguration(cfg, cloudCfg.Defaults)
-	envCfg, hasEnv := cloudCfg.Overrides[cr.environment]
-	if !hasEnv {
-		return nil, fmt.Errorf("the deployment env %s is not found under cloud %s", cr.environment, cr.cloud)
+	warnings, err := cr.deprecationWarnings(layers)
+	if err != nil {
+		return nil, nil, err
 	}
-	cfg = types.MergeConfiguration(cfg, envCfg.Defaults)
-	regionCfg, hasRegion := envCfg.Overrides[region]
-	if !hasRegion {
-		// a missing region just means we use default values
-		regionCfg = types.Configuration{}
+	if cr.strictDeprecations && len(warnings) > 0 {
+		return nil, warnings, &DeprecationError{Warnings: warnings}
 	}
-	cfg = types.MergeConfiguration(cfg, regionCfg)
-	return cfg, nil
+	return mergeLayers(layers), warnings, nil
 }
 
 // GetConfiguration merges values to resolve the configuration for this cloud and environment.
 func (cr *configResolver) GetConfiguration() (types.Configuration, error) {
-	cfg := cr.cfg.Defaults
+	return cr.resolve("", false)
+}
+
+// resolve merges the layers of overrides for a region, or for no region at all, caching the result. Callers receive
+// copies, so they may mutate what they are given.
+func (cr *configResolver) resolve(region string, includeRegion bool) (types.Configuration, error) {
+	if !includeRegion {
+		base, err := cr.base()
+		if err != nil {
+			return nil, err
+		}
+		return base.DeepCopy(), nil
+	}
+
+	// region names are never empty, so this can't collide with the key for no region
+	key := "region/" + region
+	if cached, ok := cr.cached(key); ok {
+		return cached.DeepCopy(), nil
+	}
+
+	layers, err := cr.layers(region, includeRegion)
+	if err != nil {
+		return nil, err
+	}
+	if cr.strictDeprecations {
+		if err := cr.checkDeprecations(layers); err != nil {
+			return nil, err
+		}
+	}
+	// the default, cloud and environment layers are merged once, and every region is merged onto them
+	base, err := cr.base()
+	if err != nil {
+		return nil, err
+	}
+	merged := mergeLayers(append([]overrideLayer{{name: "environment", cfg: base}}, layers[3:]...))
+	cr.cache(key, merged)
+	return merged.DeepCopy(), nil
+}
+
+// base merges the default, cloud and environment layers, caching the result. Callers must not mutate what they are
+// given.
+func (cr *configResolver) base() (types.Configuration, error) {
+	if cached, ok := cr.cached(""); ok {
+		return cached, nil
+	}
+	layers, err := cr.layers("", false)
+	if err != nil {
+		return nil, err
+	}
+	if cr.strictDeprecations {
+		if err := cr.checkDeprecations(layers); err != nil {
+			return nil, err
+		}
+	}
+	merged := mergeLayers(layers)
+	cr.cache("", merged)
+	return merged, nil
+}
+
+func (cr *configResolver) cached(key string) (types.Configuration, bool) {
+	cr.mergedLock.Lock()
+	defer cr.mergedLock.Unlock()
+	cached, ok := cr.merged[key]
+	return cached, ok
+}
+
+func (cr *configResolver) cache(key string, merged types.Configuration) {
+	cr.mergedLock.Lock()
+	defer cr.mergedLock.Unlock()
+	if cr.merged == nil {
+		cr.merged = map[string]types.Configuration{}
+	}
+	cr.merged[key] = merged
+}
+
+// layers divulges the levels of overrides that contribute to the configuration, in order of precedence.
+func (cr *configResolver) layers(region string, includeRegion bool) ([]overrideLayer, error) {
 	cloudCfg, hasCloud := cr.cfg.Overrides[cr.cloud]
 	if !hasCloud {
 		return nil, fmt.Errorf("the cloud %s is not found in the config", cr.cloud)
 	}
-	cfg = types.MergeConfiguration(cfg, cloudCfg.Defaults)
 	envCfg, hasEnv := cloudCfg.Overrides[cr.environment]
 	if !hasEnv {
 		return nil, fmt.Errorf("the deployment env %s is not found under cloud %s", cr.environment, cr.cloud)
 	}
-	cfg = types.MergeConfiguration(cfg, envCfg.Defaults)
+	layers := []overrideLayer{
+		{name: "default", cfg: cr.cfg.Defaults},
+		{name: "cloud", cfg: cloudCfg.Defaults},
+		{name: "environment", cfg: envCfg.Defaults},
+	}
+	if includeRegion {
+		regionCfg, stampCfg := cr.regionAndStampOverrides(envCfg, region)
+		layers = append(layers, overrideLayer{name: "region", cfg: regionCfg}, overrideLayer{name: "stamp", cfg: stampCfg})
+	}
+	return layers, nil
+}
 
-	return cfg, nil
+// regionAndStampOverrides fetches the overrides for a region and for the resolver's stamp in it; missing
+// regions or stamps just mean we use default values.
+func (cr *configResolver) regionAndStampOverrides(envCfg *environmentOverrides, region string) (types.Configuration, types.Configuration) {
+	regionCfg, hasRegion := envCfg.Overrides[region]
+	if !hasRegion || regionCfg == nil {
+		return types.Configuration{}, types.Configuration{}
+	}
+	regionDefaults := regionCfg.Defaults
+	if regionDefaults == nil {
+		regionDefaults = types.Configuration{}
+	}
+	stampCfg, hasStamp := regionCfg.Stamps[cr.stamp]
+	if !hasStamp || stampCfg == nil {
+		stampCfg = types.Configuration{}
+	}
+	return regionDefaults, stampCfg
+}
+
+func mergeLayers(layers []overrideLayer) types.Configuration {
+	var cfg types.Configuration
+	for i, layer := range layers {
+		if i == 0 {
+			cfg = layer.cfg
+			continue
+		}
+		cfg = types.MergeConfiguration(cfg, layer.cfg)
+	}
+	return cfg
+}
+
+func (cr *configResolver) deprecationWarnings(layers []overrideLayer) ([]DeprecationWarning, error) {
+	if !cr.hasSchema() {
+		return nil, nil
+	}
+	document, err := cr.schemaDocument()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schema: %w", err)
+	}
+	deprecations, err := findDeprecations(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load deprecations: %w", err)
+	}
+	return deprecationWarnings(deprecations, layers), nil
 }
 
-// GetRegionOverrides resolves the overrides for a region.
+func (cr *configResolver) checkDeprecations(layers []overrideLayer) error {
+	warnings, err := cr.deprecationWarnings(layers)
+	if err != nil {
+		return err
+	}
+	if len(warnings) > 0 {
+		return &DeprecationError{Warnings: warnings}
+	}
+	return nil
+}
+
+// GetRegionOverrides resolves the overrides for a region, including those for the stamp in that region.
 func (cr *configResolver) GetRegionOverrides(region string) (types.Configuration, error) {
 	cloudCfg, hasCloud := cr.cfg.Overrides[cr.cloud]
 	if !hasCloud {
@@ -301,12 +605,11 @@ func (cr *configResolver) GetRegionOverrides(region string) (types.Configuration
 	if !hasEnv {
 		return nil, fmt.Errorf("the deployment env %s is not found under cloud %s", cr.environment, cr.cloud)
 	}
-	regionCfg, hasRegion := envCfg.Overrides[region]
-	if !hasRegion {
-		// a missing region just means we use default values
-		regionCfg = types.Configuration{}
+	regionCfg, stampCfg := cr.regionAndStampOverrides(envCfg, region)
+	if len(stampCfg) == 0 {
+		return regionCfg, nil
 	}
-	return regionCfg, nil
+	return types.MergeConfiguration(regionCfg, stampCfg), nil
 }
 
 type Provenance struct {
@@ -322,6 +625,9 @@ type Provenance struct {
 	Region    any
 	RegionSet bool
 
+	Stamp    any
+	StampSet bool
+
 	Result    any
 	ResultSet bool
 }
@@ -329,42 +635,31 @@ type Provenance struct {
 // ValueProvenance determines the provenance of a value in the configuration - which levels of overrides have something to do
 // with this value, how do they override each other, what is the resulting value?
 func (cr *configResolver) ValueProvenance(region, path string) (*Provenance, error) {
-	cloudCfg, hasCloud := cr.cfg.Overrides[cr.cloud]
-	if !hasCloud {
-		return nil, fmt.Errorf("the cloud %s is not found in the config", cr.cloud)
-	}
-	envCfg, hasEnv := cloudCfg.Overrides[cr.environment]
-	if !hasEnv {
-		return nil, fmt.Errorf("the deployment env %s is not found under cloud %s", cr.environment, cr.cloud)
-	}
-	regionCfg, hasRegion := envCfg.Overrides[region]
-	if !hasRegion {
-		// a missing region just means we use default values
-		regionCfg = types.Configuration{}
-	}
-
-	mergedCfg, err := cr.GetRegionConfiguration(region)
+	layers, err := cr.layers(region, true)
 	if err != nil {
 		return nil, err
 	}
+	mergedCfg := mergeLayers(layers)
 
 	p := &Provenance{}
-	for name, part := range map[string]struct {
-		from  *types.Configuration
+	parts := map[string]struct {
 		value *any
 		set   *bool
 	}{
-		"default":     {from: &cr.cfg.Defaults, value: &p.Default, set: &p.DefaultSet},
-		"cloud":       {from: &cloudCfg.Defaults, value: &p.Cloud, set: &p.CloudSet},
-		"environment": {from: &envCfg.Defaults, value: &p.Environment, set: &p.EnvironmentSet},
-		"region":      {from: &regionCfg, value: &p.Region, set: &p.RegionSet},
-		"result":      {from: &mergedCfg, value: &p.Result, set: &p.ResultSet},
-	} {
-		val, err := part.from.GetByPath(path)
+		"default":     {value: &p.Default, set: &p.DefaultSet},
+		"cloud":       {value: &p.Cloud, set: &p.CloudSet},
+		"environment": {value: &p.Environment, set: &p.EnvironmentSet},
+		"region":      {value: &p.Region, set: &p.RegionSet},
+		"stamp":       {value: &p.Stamp, set: &p.StampSet},
+		"result":      {value: &p.Result, set: &p.ResultSet},
+	}
+	for _, layer := range append(layers, overrideLayer{name: "result", cfg: mergedCfg}) {
+		part := parts[layer.name]
+		val, err := layer.cfg.GetByPath(path)
 		var missingKeyErr *types.MissingKeyError
 		isMissing := errors.As(err, &missingKeyErr)
 		if err != nil && !isMissing {
-			return nil, fmt.Errorf("failed to get value from %s config: %w", name, err)
+			return nil, fmt.Errorf("failed to get value from %s config: %w", layer.name, err)
 		}
 		*part.value = val
 		*part.set = !isMissing
@@ -379,7 +674,7 @@ func PreprocessFile(templateFilePath string, vars map[string]any) ([]byte, error
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file %s: %w", templateFilePath, err)
 	}
-	processedContent, err := PreprocessContent(content, vars)
+	processedContent, err := PreprocessFileContent(templateFilePath, content, vars)
 	if err != nil {
 		return nil, fmt.Errorf("failed to preprocess content %s: %w", templateFilePath, err)
 	}
@@ -388,21 +683,67 @@ func PreprocessFile(templateFilePath string, vars map[string]any) ([]byte, error
 
 // PreprocessContent processes a gotemplate from memory
 func PreprocessContent(content []byte, vars map[string]any) ([]byte, error) {
+	return PreprocessFileContent("", content, vars)
+}
+
+// PreprocessFileContent processes a gotemplate read from a file, so that errors can name the file.
+func PreprocessFileContent(file string, content []byte, vars map[string]any) ([]byte, error) {
 	var tmplBytes bytes.Buffer
-	if err := PreprocessContentIntoWriter(content, vars, &tmplBytes); err != nil {
+	if err := preprocessIntoWriter(file, content, vars, &tmplBytes); err != nil {
 		return nil, err
 	}
 	return tmplBytes.Bytes(), nil
 }
 
 func PreprocessContentIntoWriter(content []byte, vars map[string]any, writer io.Writer) error {
-	tmpl, err := template.New("file").Parse(string(content))
+	return preprocessIntoWriter("", content, vars, writer)
+}
+
+func preprocessIntoWriter(file string, content []byte, vars map[string]any, writer io.Writer) error {
+	tmpl, err := parseTemplate(file, content)
 	if err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
+		return err
 	}
+	return tmpl.executeIntoWriter(vars, writer)
+}
 
-	if err := tmpl.Option("missingkey=error").Execute(writer, vars); err != nil {
-		return fmt.Errorf("failed to execute template: %w", err)
+// parsedTemplate holds a template along with its source, so that errors can point at the offending line.
+type parsedTemplate struct {
+	file   string
+	source []string
+	tmpl   *template.Template
+}
+
+// parseTemplate parses content as a template that fails on missing keys. The result is safe to execute concurrently.
+// Errors from parsing and executing the template are *TemplateErrors.
+func parseTemplate(file string, content []byte) (*parsedTemplate, error) {
+	name := file
+	if name == "" {
+		name = "file"
+	}
+	t := &parsedTemplate{
+		file:   file,
+		source: strings.Split(string(content), "\n"),
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", newTemplateError(file, t.source, nil, err))
+	}
+	t.tmpl = tmpl
+	return t, nil
+}
+
+func (t *parsedTemplate) execute(vars map[string]any) ([]byte, error) {
+	var tmplBytes bytes.Buffer
+	if err := t.executeIntoWriter(vars, &tmplBytes); err != nil {
+		return nil, err
+	}
+	return tmplBytes.Bytes(), nil
+}
+
+func (t *parsedTemplate) executeIntoWriter(vars map[string]any, writer io.Writer) error {
+	if err := t.tmpl.Execute(writer, vars); err != nil {
+		return fmt.Errorf("failed to execute template: %w", newTemplateError(t.file, t.source, vars, err))
 	}
 	return nil
 }
@@ -410,25 +751,97 @@ func PreprocessContentIntoWriter(content []byte, vars map[string]any, writer io.
 //go:embed config.schema.v1.json
 var configSchemaV1Content []byte
 
-const schemaRef = "config.meta.schema.v1.json"
+//go:embed config.schema.v2.json
+var configSchemaV2Content []byte
+
+const (
+	metaSchemaV1Ref = "config.meta.schema.v1.json"
+	metaSchemaV2Ref = "config.meta.schema.v2.json"
+
+	// LatestMetaSchema is the most recent version of the configuration file layout.
+	LatestMetaSchema = metaSchemaV2Ref
+)
+
+// defaultMetaSchemaRef is assumed for files that do not declare $metaSchema, as they predate versioning.
+var defaultMetaSchemaRef = metaSchemaV1Ref
+
+// metaSchemaRefFor determines which version of the meta-schema a configuration file declares.
+func metaSchemaRefFor(config map[string]any) (string, error) {
+	raw, set := config["$metaSchema"]
+	if !set {
+		return defaultMetaSchemaRef, nil
+	}
+	ref, ok := raw.(string)
+	if !ok {
+		return "", fmt.Errorf("$metaSchema must be a string, got %T", raw)
+	}
+	if ref == "" {
+		return defaultMetaSchemaRef, nil
+	}
+	return ref, nil
+}
 
 func ValidateConfigMetaSchema(config map[string]any) error {
-	pipelineSchema, err := compileSchema()
+	schemaRef, err := metaSchemaRefFor(config)
+	if err != nil {
+		return err
+	}
+	metaSchema, err := getMetaSchemaForRef(schemaRef)
 	if err != nil {
-		return fmt.Errorf("failed to load pipeline schema: %v", err)
+		return fmt.Errorf("failed to load meta schema: %v", err)
 	}
 
-	err = pipelineSchema.Validate(config)
+	err = metaSchema.Validate(config)
 	if err != nil {
 		return fmt.Errorf("config is not compliant with meta schema %s: %v", schemaRef, err)
 	}
 	return nil
 }
 
-func compileSchema() (*jsonschema.Schema, error) {
+func getMetaSchemaForRef(schemaRef string) (*jsonschema.Schema, error) {
+	switch schemaRef {
+	case metaSchemaV1Ref:
+		return compileSchema(schemaRef, configSchemaV1Content)
+	case metaSchemaV2Ref:
+		return compileSchema(schemaRef, configSchemaV2Content)
+	default:
+		return nil, fmt.Errorf("unsupported meta schema reference: %s", schemaRef)
+	}
+}
+
+// loadConfigurationOverrides unmarshals pre-processed configuration content using the loader for the version of the
+// meta-schema that the content declares.
+func loadConfigurationOverrides(rawContent []byte) (configurationOverrides, error) {
+	versioned := map[string]any{}
+	if err := yaml.Unmarshal(rawContent, &versioned); err != nil {
+		return configurationOverrides{}, err
+	}
+	schemaRef, err := metaSchemaRefFor(versioned)
+	if err != nil {
+		return configurationOverrides{}, err
+	}
+	switch schemaRef {
+	case metaSchemaV1Ref:
+		var v1 configurationOverridesV1
+		if err := yaml.Unmarshal(rawContent, &v1); err != nil {
+			return configurationOverrides{}, err
+		}
+		return v1.convert(), nil
+	case metaSchemaV2Ref:
+		var current configurationOverrides
+		if err := yaml.Unmarshal(rawContent, &current); err != nil {
+			return configurationOverrides{}, err
+		}
+		return current, nil
+	default:
+		return configurationOverrides{}, fmt.Errorf("unsupported meta schema reference: %s", schemaRef)
+	}
+}
+
+func compileSchema(schemaRef string, schemaContent []byte) (*jsonschema.Schema, error) {
 	// parse schema content
 	schemaMap := make(map[string]interface{})
-	err := json.Unmarshal(configSchemaV1Content, &schemaMap)
+	err := json.Unmarshal(schemaContent, &schemaMap)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal schema content: %v", err)
 	}
@@ -439,10 +852,10 @@ func compileSchema() (*jsonschema.Schema, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to add schema resource %s: %v", schemaRef, err)
 	}
-	pipelineSchema, err := c.Compile(schemaRef)
+	metaSchema, err := c.Compile(schemaRef)
 	if err != nil {
 		return nil, fmt.Errorf("failed to compile schema %s: %v", schemaRef, err)
 	}
 
-	return pipelineSchema, nil
+	return metaSchema, nil
 }
@@ -17,12 +17,15 @@ package config_test
 import (
 	"os"
 	"testing"
+	"testing/fstest"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/stretchr/testify/require"
 
 	"github.com/Azure/ARO-Tools/internal/testutil"
 	"github.com/Azure/ARO-Tools/pkg/config"
+	"github.com/Azure/ARO-Tools/pkg/config/configtest"
 	"github.com/Azure/ARO-Tools/pkg/config/ev2config"
 	"github.com/Azure/ARO-Tools/pkg/config/types"
 )
@@ -357,6 +360,57 @@ func TestTruncateConfiguration(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name:        "invalid path - partial wildcard",
+			config:      types.Configuration{"key1": "value1"},
+			paths:       []string{"key*"},
+			expected:    nil,
+			expectError: true,
+			errorMsg:    "wildcards must be whole segments",
+		},
+		{
+			name:        "wildcard matching nothing",
+			config:      types.Configuration{"key1": "value1"},
+			paths:       []string{"key1", "svc.*.password"},
+			expected:    nil,
+			expectError: true,
+			errorMsg:    "patterns matched nothing in the configuration: svc.*.password",
+		},
+		{
+			name: "truncate with single-segment wildcard",
+			config: types.Configuration{
+				"svc": map[string]any{
+					"frontend": map[string]any{"image": "fe", "password": "secret"},
+					"backend":  map[string]any{"image": "be", "password": "secret"},
+				},
+			},
+			paths: []string{"svc.*.password"},
+			expected: map[string]any{
+				"svc": map[string]any{
+					"frontend": map[string]any{"image": "fe"},
+					"backend":  map[string]any{"image": "be"},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "truncate with multi-segment wildcard",
+			config: types.Configuration{
+				"password": "top",
+				"svc": map[string]any{
+					"frontend": map[string]any{"image": "fe", "password": "secret"},
+					"tls":      map[string]any{"cert": map[string]any{"password": "secret"}},
+				},
+			},
+			paths: []string{"**.password"},
+			expected: map[string]any{
+				"svc": map[string]any{
+					"frontend": map[string]any{"image": "fe"},
+					"tls":      map[string]any{"cert": map[string]any{}},
+				},
+			},
+			expectError: false,
+		},
 		{
 			name: "deep nesting truncation",
 			config: types.Configuration{
@@ -538,3 +592,193 @@ func TestMergeRawConfigurationFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestMigrateConfiguration(t *testing.T) {
+	raw, err := os.ReadFile("testdata/migrate/config.yaml")
+	require.NoError(t, err)
+
+	migrated, version, err := config.MigrateConfiguration(raw)
+	require.NoError(t, err)
+	require.Equal(t, config.LatestMetaSchema, version)
+	testutil.CompareWithFixture(t, migrated)
+
+	_, _, err = config.MigrateConfiguration(migrated)
+	require.ErrorContains(t, err, "already uses the latest meta schema")
+
+	replacements := &config.ConfigReplacements{
+		RegionReplacement:      "uksouth",
+		RegionShortReplacement: "ln",
+		StampReplacement:       "1",
+		CloudReplacement:       "public",
+		EnvironmentReplacement: "int",
+		Ev2Config:              map[string]any{"availabilityZoneCount": 3},
+	}
+	resolved := map[string]types.Configuration{}
+	for version, content := range map[string][]byte{"original": raw, "migrated": migrated} {
+		provider, err := config.NewConfigProviderFromData(content, "testdata/migrate")
+		require.NoError(t, err)
+		resolver, err := provider.GetResolver(replacements)
+		require.NoError(t, err)
+		cfg, err := resolver.GetRegionConfiguration("uksouth")
+		require.NoError(t, err)
+		resolved[version] = cfg
+	}
+	require.Empty(t, cmp.Diff(resolved["original"], resolved["migrated"]))
+}
+
+func TestStampOverrides(t *testing.T) {
+	provider, err := config.NewConfigProvider("testdata/stamps/config.yaml")
+	require.NoError(t, err)
+	require.Equal(t, map[string]map[string][]string{"public": {"int": {"uksouth"}}}, provider.AllContexts())
+
+	for stamp, expected := range map[string]types.Configuration{
+		"1": {"key1": "uksouth", "key2": float64(1)},
+		"2": {"key1": "uksouth", "key2": float64(2)},
+	} {
+		t.Run(stamp, func(t *testing.T) {
+			resolver, err := provider.GetResolver(&config.ConfigReplacements{
+				RegionReplacement:      "uksouth",
+				RegionShortReplacement: "ln",
+				StampReplacement:       stamp,
+				CloudReplacement:       "public",
+				EnvironmentReplacement: "int",
+			})
+			require.NoError(t, err)
+
+			cfg, err := resolver.GetRegionConfiguration("uksouth")
+			require.NoError(t, err)
+			require.Empty(t, cmp.Diff(expected, cfg))
+
+			provenance, err := resolver.ValueProvenance("uksouth", "key2")
+			require.NoError(t, err)
+			require.Equal(t, stamp == "2", provenance.StampSet)
+		})
+	}
+}
+
+func TestNewConfigReplacements(t *testing.T) {
+	replacements, err := config.NewConfigReplacements("public", "int", "uksouth", "2")
+	require.NoError(t, err)
+
+	ctx := replacements.AsMap()["ctx"]
+	if diff := cmp.Diff(map[string]any{
+		"region":                "uksouth",
+		"regionShort":           "ln",
+		"regionFriendlyName":    "UK South",
+		"geography":             "United Kingdom",
+		"availabilityZoneCount": 3,
+		"stamp":                 "2",
+		"cloud":                 "public",
+		"environment":           "int",
+	}, ctx); diff != "" {
+		t.Errorf("incorrect context (-want, +got): %v", diff)
+	}
+	require.Equal(t, "UK South", replacements.Ev2Config["regionFriendlyName"])
+
+	_, err = config.NewConfigReplacements("public", "int", "nowhere", "1")
+	require.ErrorContains(t, err, "failed to find region nowhere in cloud public")
+}
+
+func TestNewConfigProviderFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"service/config.yaml": &fstest.MapFile{Data: []byte(`$schema: ../schemas/config.schema.json
+defaults:
+  replicas: 3
+  oldReplicas: 2
+clouds:
+  public:
+    environments:
+      int:
+        regions:
+          uksouth:
+            replicas: {{ .ctx.availabilityZoneCount }}
+`)},
+		"schemas/config.schema.json": &fstest.MapFile{Data: []byte(`{
+  "type": "object",
+  "properties": {
+    "replicas": {"$ref": "definitions.json#/definitions/replicas"},
+    "oldReplicas": {"deprecated": true, "x-replacedBy": "replicas"}
+  }
+}`)},
+		"schemas/definitions.json": &fstest.MapFile{Data: []byte(`{"definitions": {"replicas": {"type": "integer", "minimum": 3}}}`)},
+	}
+
+	provider, err := config.NewConfigProviderFromFS(fsys, "service/config.yaml")
+	require.NoError(t, err)
+	resolver := configtest.Resolver(t, provider, "public", "int", "uksouth")
+
+	schemaPath, err := resolver.SchemaPath()
+	require.NoError(t, err)
+	require.Equal(t, "schemas/config.schema.json", schemaPath, "the path is within the filesystem")
+	document, err := resolver.SchemaDocument()
+	require.NoError(t, err)
+	require.Contains(t, document["properties"], "replicas")
+
+	cfg, warnings, err := resolver.GetRegionConfigurationWithWarnings("uksouth")
+	require.NoError(t, err)
+	require.Equal(t, []config.DeprecationWarning{{Path: "oldReplicas", Level: "default", ReplacedBy: "replicas"}}, warnings)
+	require.NoError(t, resolver.ValidateSchema(cfg))
+
+	cfg["replicas"] = 1
+	require.ErrorContains(t, resolver.ValidateSchema(cfg), "minimum")
+
+	fsys["service/config.yaml"] = &fstest.MapFile{Data: []byte("$schema: ../../config.schema.json\n")}
+	_, err = config.NewConfigProviderFromFS(fsys, "service/config.yaml")
+	require.ErrorContains(t, err, "outside of the filesystem")
+}
+
+func TestTemplateError(t *testing.T) {
+	vars := map[string]any{
+		"ctx": map[string]any{"region": "uksouth", "regionShort": "ln"},
+		"svc": map[string]any{"name": "svc", "image": map[string]any{"digest": "sha256:abc"}},
+	}
+	for _, testCase := range []struct {
+		name    string
+		content string
+		want    *config.TemplateError
+		message string
+	}{
+		{
+			name:    "missing nested key",
+			content: "name: svc\nimage: {{ .svc.image.digets }}\n",
+			want: &config.TemplateError{
+				File: "file.yaml", Line: 2, Column: 11,
+				Snippet:     "image: {{ .svc.image.digets }}",
+				MissingKey:  "svc.image.digets",
+				Suggestions: []string{"svc.image.digest"},
+			},
+			message: "file.yaml:2:11: missing key svc.image.digets, did you mean svc.image.digest?\n\timage: {{ .svc.image.digets }}\n\t          ^",
+		},
+		{
+			name:    "missing intermediate key",
+			content: "region: {{ .cxt.region }}",
+			want: &config.TemplateError{
+				File: "file.yaml", Line: 1, Column: 12,
+				Snippet:     "region: {{ .cxt.region }}",
+				MissingKey:  "cxt",
+				Suggestions: []string{"ctx"},
+			},
+		},
+		{
+			name:    "parse error",
+			content: "a: b\nregion: {{ .ctx.region }\n",
+			want: &config.TemplateError{
+				File: "file.yaml", Line: 2,
+				Snippet: "region: {{ .ctx.region }",
+			},
+			message: "file.yaml:2: unexpected \"}\" in operand\n\tregion: {{ .ctx.region }",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := config.PreprocessFileContent("file.yaml", []byte(testCase.content), vars)
+			var templateErr *config.TemplateError
+			require.ErrorAs(t, err, &templateErr)
+			if diff := cmp.Diff(testCase.want, templateErr, cmpopts.IgnoreFields(config.TemplateError{}, "Err")); diff != "" {
+				t.Errorf("incorrect error (-want, +got): %v", diff)
+			}
+			if testCase.message != "" {
+				require.Equal(t, testCase.message, templateErr.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package configtest assembles configuration providers in memory, so that tests of code consuming service
+// configuration do not need to maintain configuration files and schemas on disk.
+package configtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/yaml"
+
+	"github.com/Azure/ARO-Tools/pkg/config"
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+// schemaPath is recorded as the $schema of built configurations; the schema itself is only ever held in memory.
+const schemaPath = "/configtest/config.schema.json"
+
+// PermissiveSchema accepts any configuration.
+var PermissiveSchema = []byte(`{"type": "object"}`)
+
+// Builder assembles a configuration file in memory, one layer of overrides at a time.
+type Builder struct {
+	defaults types.Configuration
+	clouds   map[string]*cloudOverrides
+	schema   []byte
+	options  []config.ProviderOption
+}
+
+type cloudOverrides struct {
+	Defaults     types.Configuration              `json:"defaults,omitempty"`
+	Environments map[string]*environmentOverrides `json:"environments,omitempty"`
+}
+
+type environmentOverrides struct {
+	Defaults types.Configuration         `json:"defaults,omitempty"`
+	Regions  map[string]*regionOverrides `json:"regions,omitempty"`
+}
+
+type regionOverrides struct {
+	Defaults types.Configuration            `json:"defaults,omitempty"`
+	Stamps   map[string]types.Configuration `json:"stamps,omitempty"`
+}
+
+// NewBuilder creates an empty configuration builder.
+func NewBuilder() *Builder {
+	return &Builder{
+		defaults: types.Configuration{},
+		clouds:   map[string]*cloudOverrides{},
+	}
+}
+
+// WithDefaults merges values into the global defaults.
+func (b *Builder) WithDefaults(values types.Configuration) *Builder {
+	b.defaults = types.MergeConfiguration(b.defaults, values)
+	return b
+}
+
+// WithCloud merges values into the defaults for a cloud, registering the cloud if necessary.
+func (b *Builder) WithCloud(cloud string, values types.Configuration) *Builder {
+	c := b.cloud(cloud)
+	c.Defaults = types.MergeConfiguration(c.Defaults, values)
+	return b
+}
+
+// WithEnvironment merges values into the defaults for an environment, registering the cloud and environment if necessary.
+func (b *Builder) WithEnvironment(cloud, environment string, values types.Configuration) *Builder {
+	e := b.environment(cloud, environment)
+	e.Defaults = types.MergeConfiguration(e.Defaults, values)
+	return b
+}
+
+// WithRegion merges values into the overrides for a region, registering the cloud, environment and region if necessary.
+func (b *Builder) WithRegion(cloud, environment, region string, values types.Configuration) *Builder {
+	r := b.region(cloud, environment, region)
+	r.Defaults = types.MergeConfiguration(r.Defaults, values)
+	return b
+}
+
+// WithStamp merges values into the overrides for a stamp in a region, registering every parent level if necessary.
+func (b *Builder) WithStamp(cloud, environment, region, stamp string, values types.Configuration) *Builder {
+	r := b.region(cloud, environment, region)
+	if r.Stamps == nil {
+		r.Stamps = map[string]types.Configuration{}
+	}
+	r.Stamps[stamp] = types.MergeConfiguration(r.Stamps[stamp], values)
+	return b
+}
+
+// WithSchema registers the service schema that resolved configurations are validated against.
+func (b *Builder) WithSchema(schema []byte) *Builder {
+	b.schema = schema
+	return b
+}
+
+// WithPermissiveSchema registers a service schema that accepts any configuration.
+func (b *Builder) WithPermissiveSchema() *Builder {
+	return b.WithSchema(PermissiveSchema)
+}
+
+// WithProviderOptions passes options through to the configuration provider.
+func (b *Builder) WithProviderOptions(opts ...config.ProviderOption) *Builder {
+	b.options = append(b.options, opts...)
+	return b
+}
+
+func (b *Builder) cloud(cloud string) *cloudOverrides {
+	if _, exists := b.clouds[cloud]; !exists {
+		b.clouds[cloud] = &cloudOverrides{Defaults: types.Configuration{}}
+	}
+	return b.clouds[cloud]
+}
+
+func (b *Builder) environment(cloud, environment string) *environmentOverrides {
+	c := b.cloud(cloud)
+	if c.Environments == nil {
+		c.Environments = map[string]*environmentOverrides{}
+	}
+	if _, exists := c.Environments[environment]; !exists {
+		c.Environments[environment] = &environmentOverrides{Defaults: types.Configuration{}}
+	}
+	return c.Environments[environment]
+}
+
+func (b *Builder) region(cloud, environment, region string) *regionOverrides {
+	e := b.environment(cloud, environment)
+	if e.Regions == nil {
+		e.Regions = map[string]*regionOverrides{}
+	}
+	if _, exists := e.Regions[region]; !exists {
+		e.Regions[region] = &regionOverrides{Defaults: types.Configuration{}}
+	}
+	return e.Regions[region]
+}
+
+// Raw renders the configuration file that the builder has assembled.
+func (b *Builder) Raw() ([]byte, error) {
+	document := map[string]any{
+		"$metaSchema": config.LatestMetaSchema,
+		"defaults":    b.defaults,
+	}
+	if len(b.clouds) > 0 {
+		document["clouds"] = b.clouds
+	}
+	if b.schema != nil {
+		document["$schema"] = schemaPath
+	}
+	raw, err := yaml.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	return raw, nil
+}
+
+// Build creates a configuration provider for the assembled configuration, without touching the filesystem.
+func (b *Builder) Build() (config.ConfigProvider, error) {
+	raw, err := b.Raw()
+	if err != nil {
+		return nil, err
+	}
+	opts := b.options
+	if b.schema != nil {
+		opts = append([]config.ProviderOption{config.WithSchemaContent(b.schema)}, opts...)
+	}
+	return config.NewConfigProviderFromData(raw, "/", opts...)
+}
+
+// MustBuild creates a configuration provider for the assembled configuration, failing the test on error.
+func (b *Builder) MustBuild(t testing.TB) config.ConfigProvider {
+	t.Helper()
+	provider, err := b.Build()
+	if err != nil {
+		t.Fatalf("failed to build configuration provider: %v", err)
+	}
+	return provider
+}
+
+// Resolver creates a resolver for the context, using the Ev2 catalog for the cloud and region and failing the test on
+// error.
+func Resolver(t testing.TB, provider config.ConfigProvider, cloud, environment, region string) config.ConfigResolver {
+	t.Helper()
+	replacements, err := config.NewConfigReplacements(cloud, environment, region, "")
+	if err != nil {
+		t.Fatalf("failed to create replacements: %v", err)
+	}
+	resolver, err := provider.GetResolver(replacements)
+	if err != nil {
+		t.Fatalf("failed to get resolver for %s/%s: %v", cloud, environment, err)
+	}
+	return resolver
+}
+
+// AssertProvenance asserts that the provenance of the value at path matches what is expected.
+func AssertProvenance(t testing.TB, resolver config.ConfigResolver, region, path string, want *config.Provenance) {
+	t.Helper()
+	got, err := resolver.ValueProvenance(region, path)
+	if err != nil {
+		t.Fatalf("failed to determine provenance of %s: %v", path, err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("incorrect provenance for %s (-want, +got): %v", path, diff)
+	}
+}
+
+// AssertSetBy asserts that exactly the given levels of overrides set the value at path, and that it resolves to value.
+// Levels are named default, cloud, environment, region and stamp.
+func AssertSetBy(t testing.TB, resolver config.ConfigResolver, region, path string, value any, levels ...string) {
+	t.Helper()
+	got, err := resolver.ValueProvenance(region, path)
+	if err != nil {
+		t.Fatalf("failed to determine provenance of %s: %v", path, err)
+	}
+	if diff := cmp.Diff(levels, SetBy(got)); diff != "" {
+		t.Errorf("incorrect levels setting %s (-want, +got): %v", path, diff)
+	}
+	if diff := cmp.Diff(value, got.Result); diff != "" {
+		t.Errorf("incorrect value for %s (-want, +got): %v", path, diff)
+	}
+}
+
+// SetBy lists the levels of overrides that set a value, from least to most specific.
+func SetBy(p *config.Provenance) []string {
+	var levels []string
+	for _, level := range []struct {
+		name string
+		set  bool
+	}{
+		{name: "default", set: p.DefaultSet},
+		{name: "cloud", set: p.CloudSet},
+		{name: "environment", set: p.EnvironmentSet},
+		{name: "region", set: p.RegionSet},
+		{name: "stamp", set: p.StampSet},
+	} {
+		if level.set {
+			levels = append(levels, level.name)
+		}
+	}
+	return levels
+}
@@ -0,0 +1,75 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configtest_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Azure/ARO-Tools/pkg/config"
+	"github.com/Azure/ARO-Tools/pkg/config/configtest"
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+func TestBuilder(t *testing.T) {
+	provider := configtest.NewBuilder().
+		WithDefaults(types.Configuration{"replicas": 1, "svc": map[string]any{"name": "default", "image": "base"}}).
+		WithCloud("public", types.Configuration{"svc": map[string]any{"name": "public"}}).
+		WithEnvironment("public", "int", types.Configuration{"replicas": 2}).
+		WithRegion("public", "int", "uksouth", types.Configuration{"replicas": 3}).
+		WithRegion("public", "int", "eastus", nil).
+		WithStamp("public", "int", "uksouth", "1", types.Configuration{"replicas": 4}).
+		WithPermissiveSchema().
+		MustBuild(t)
+
+	if diff := cmp.Diff(map[string]map[string][]string{"public": {"int": {"eastus", "uksouth"}}}, provider.AllContexts(), cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+		t.Errorf("incorrect contexts (-want, +got): %v", diff)
+	}
+
+	resolver := configtest.Resolver(t, provider, "public", "int", "uksouth")
+	cfg, err := resolver.GetRegionConfiguration("uksouth")
+	require.NoError(t, err)
+	require.NoError(t, resolver.ValidateSchema(cfg))
+	if diff := cmp.Diff(types.Configuration{"replicas": float64(3), "svc": map[string]any{"name": "public", "image": "base"}}, cfg); diff != "" {
+		t.Errorf("incorrect configuration (-want, +got): %v", diff)
+	}
+
+	configtest.AssertSetBy(t, resolver, "uksouth", "replicas", float64(3), "default", "environment", "region")
+	configtest.AssertSetBy(t, resolver, "eastus", "svc.name", "public", "default", "cloud")
+	configtest.AssertProvenance(t, resolver, "eastus", "svc.image", &config.Provenance{
+		Default: "base", DefaultSet: true,
+		Result: "base", ResultSet: true,
+	})
+
+	stamped, err := provider.GetResolver(&config.ConfigReplacements{CloudReplacement: "public", EnvironmentReplacement: "int", StampReplacement: "1"})
+	require.NoError(t, err)
+	configtest.AssertSetBy(t, stamped, "uksouth", "replicas", float64(4), "default", "environment", "region", "stamp")
+}
+
+func TestBuilderSchema(t *testing.T) {
+	provider := configtest.NewBuilder().
+		WithDefaults(types.Configuration{"replicas": "many"}).
+		WithRegion("public", "int", "uksouth", nil).
+		WithSchema([]byte(`{"type": "object", "properties": {"replicas": {"type": "integer"}}}`)).
+		MustBuild(t)
+
+	resolver := configtest.Resolver(t, provider, "public", "int", "uksouth")
+	cfg, err := resolver.GetRegionConfiguration("uksouth")
+	require.NoError(t, err)
+	require.ErrorContains(t, resolver.ValidateSchema(cfg), "failed to validate schema")
+}
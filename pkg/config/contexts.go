@@ -0,0 +1,141 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+// ResolvedContext holds the configuration resolved for one cloud, environment and region.
+type ResolvedContext struct {
+	Cloud       string
+	Environment string
+	Region      string
+
+	Replacements  *ConfigReplacements
+	Resolver      ConfigResolver
+	Configuration types.Configuration
+}
+
+// String formats the context as cloud/environment/region.
+func (c ResolvedContext) String() string {
+	return fmt.Sprintf("%s/%s/%s", c.Cloud, c.Environment, c.Region)
+}
+
+// ResolveAllContexts resolves the configuration for every context the provider has explicit records for, in a
+// stable order. Replacements for each context are looked up in the Ev2 catalog.
+func ResolveAllContexts(provider ConfigProvider) ([]ResolvedContext, error) {
+	return ResolveAllContextsInParallel(provider, 1)
+}
+
+// ResolveAllContextsInParallel resolves the configuration for every context like ResolveAllContexts, using up to
+// parallelism goroutines at once, or GOMAXPROCS goroutines if parallelism is not positive. The order of the results is
+// stable, regardless of parallelism. Each context is resolved by its own resolver, processing the template with the
+// context, so merges are not shared between contexts.
+func ResolveAllContextsInParallel(provider ConfigProvider, parallelism int) ([]ResolvedContext, error) {
+	if parallelism < 1 {
+		parallelism = runtime.GOMAXPROCS(0)
+	}
+	type context struct {
+		cloud, environment, region string
+	}
+	var contexts []context
+	all := provider.AllContexts()
+	for _, cloud := range sortedKeys(all) {
+		for _, environment := range sortedKeys(all[cloud]) {
+			regions := append([]string{}, all[cloud][environment]...)
+			sort.Strings(regions)
+			for _, region := range regions {
+				contexts = append(contexts, context{cloud: cloud, environment: environment, region: region})
+			}
+		}
+	}
+
+	resolved := make([]ResolvedContext, len(contexts))
+	group := errgroup.Group{}
+	group.SetLimit(parallelism)
+	for i, c := range contexts {
+		group.Go(func() error {
+			ctx, err := ResolveContext(provider, c.cloud, c.environment, c.region)
+			if err != nil {
+				return fmt.Errorf("%s/%s/%s: %w", c.cloud, c.environment, c.region, err)
+			}
+			resolved[i] = ctx
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// ResolveContext resolves the configuration for one context, looking up its replacements in the Ev2 catalog.
+func ResolveContext(provider ConfigProvider, cloud, environment, region string) (ResolvedContext, error) {
+	replacements, err := NewConfigReplacements(cloud, environment, region, "")
+	if err != nil {
+		return ResolvedContext{}, err
+	}
+	resolver, err := provider.GetResolver(replacements)
+	if err != nil {
+		return ResolvedContext{}, fmt.Errorf("failed to get resolver: %w", err)
+	}
+	cfg, err := resolver.GetRegionConfiguration(region)
+	if err != nil {
+		return ResolvedContext{}, fmt.Errorf("failed to resolve configuration: %w", err)
+	}
+	return ResolvedContext{
+		Cloud:         cloud,
+		Environment:   environment,
+		Region:        region,
+		Replacements:  replacements,
+		Resolver:      resolver,
+		Configuration: cfg,
+	}, nil
+}
+
+// OverridingRegions lists the regions of the resolver's cloud and environment whose overrides, or the overrides of the
+// resolver's stamp in them, set the value at the path, sorted.
+func OverridingRegions(resolver ConfigResolver, path string) ([]string, error) {
+	regions, err := resolver.GetRegions()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(regions)
+
+	overriding := []string{}
+	for _, region := range regions {
+		overrides, err := resolver.GetRegionOverrides(region)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get overrides for region %s: %w", region, err)
+		}
+		if _, err := overrides.GetByPath(path); err != nil {
+			var missingKeyErr *types.MissingKeyError
+			if errors.As(err, &missingKeyErr) {
+				continue
+			}
+			return nil, fmt.Errorf("region %s: %w", region, err)
+		}
+		overriding = append(overriding, region)
+	}
+	return overriding, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Azure/ARO-Tools/pkg/config"
+	"github.com/Azure/ARO-Tools/pkg/config/configtest"
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+func TestResolveAllContextsInParallel(t *testing.T) {
+	provider, err := config.NewConfigProvider("../../testdata/config.yaml")
+	require.NoError(t, err)
+
+	sequential, err := config.ResolveAllContexts(provider)
+	require.NoError(t, err)
+	// parallelism which isn't positive uses GOMAXPROCS goroutines
+	for _, parallelism := range []int{8, 0, -1} {
+		parallel, err := config.ResolveAllContextsInParallel(provider, parallelism)
+		require.NoError(t, err)
+
+		require.Len(t, parallel, len(sequential))
+		for i := range sequential {
+			require.Equal(t, sequential[i].String(), parallel[i].String())
+			if diff := cmp.Diff(sequential[i].Configuration, parallel[i].Configuration); diff != "" {
+				t.Errorf("%s: parallel resolution with %d differs (-sequential, +parallel): %v", sequential[i], parallelism, diff)
+			}
+		}
+	}
+}
+
+func TestOverridingRegions(t *testing.T) {
+	provider, err := config.NewConfigProviderFromData([]byte(`$metaSchema: config.meta.schema.v2.json
+defaults:
+  svc:
+    replicas: 1
+    image: default
+clouds:
+  public:
+    environments:
+      int:
+        regions:
+          uksouth:
+            defaults:
+              svc:
+                replicas: 3
+          eastus:
+            defaults:
+              svc:
+                image: eastus
+          westus3:
+            stamps:
+              "1":
+                svc:
+                  replicas: 2
+`), t.TempDir())
+	require.NoError(t, err)
+
+	resolved, err := config.ResolveContext(provider, "public", "int", "eastus")
+	require.NoError(t, err)
+	require.Equal(t, "public/int/eastus", resolved.String())
+	require.Equal(t, map[string]any{"replicas": float64(1), "image": "eastus"}, resolved.Configuration["svc"])
+
+	for path, expected := range map[string][]string{
+		"svc.replicas": {"uksouth"},
+		"svc.image":    {"eastus"},
+		"svc":          {"eastus", "uksouth"},
+		"svc.missing":  {},
+	} {
+		regions, err := config.OverridingRegions(resolved.Resolver, path)
+		require.NoError(t, err)
+		require.Equal(t, expected, regions, path)
+	}
+
+	_, err = config.OverridingRegions(resolved.Resolver, "svc.replicas.count")
+	require.ErrorContains(t, err, "expected nested map")
+}
+
+func TestResolveAllContextsDevCloud(t *testing.T) {
+	provider := configtest.NewBuilder().
+		WithDefaults(types.Configuration{"regionRG": "hcp-{{ .ctx.cloud }}-{{ .ctx.regionShort }}"}).
+		WithRegion("dev", "dev", "westus3", nil).
+		WithRegion("public", "int", "uksouth", nil).
+		MustBuild(t)
+
+	contexts, err := config.ResolveAllContexts(provider)
+	require.NoError(t, err)
+	var resolved []string
+	for _, ctx := range contexts {
+		resolved = append(resolved, fmt.Sprintf("%s: %s", ctx, ctx.Configuration["regionRG"]))
+	}
+	require.Equal(t, []string{"dev/dev/westus3: hcp-dev-usw3", "public/int/uksouth: hcp-public-ln"}, resolved)
+	require.Equal(t, "dev", contexts[0].Replacements.CloudReplacement)
+}
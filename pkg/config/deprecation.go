@@ -0,0 +1,113 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+const (
+	// deprecatedKeyword marks a property in the service schema as deprecated, using the standard JSONSchema annotation.
+	deprecatedKeyword = "deprecated"
+	// replacedByKeyword optionally records the path of the key that replaces a deprecated one.
+	replacedByKeyword = "x-replacedBy"
+)
+
+// deprecation records a deprecated key found in the service schema.
+type deprecation struct {
+	path       []string
+	replacedBy string
+}
+
+// DeprecationWarning records that a layer of overrides sets a key which the schema marks as deprecated.
+type DeprecationWarning struct {
+	// Path is the dot-separated path to the deprecated key.
+	Path string
+	// Level is the layer of overrides setting the key: one of default, cloud, environment, region or stamp.
+	Level string
+	// ReplacedBy is the dot-separated path to the key replacing the deprecated one, if the schema records it.
+	ReplacedBy string
+}
+
+func (w DeprecationWarning) String() string {
+	msg := fmt.Sprintf("%s: key %s is deprecated", w.Level, w.Path)
+	if w.ReplacedBy != "" {
+		msg += fmt.Sprintf(", use %s instead", w.ReplacedBy)
+	}
+	return msg
+}
+
+// DeprecationError is returned in strict mode when deprecated keys are set in the configuration.
+type DeprecationError struct {
+	Warnings []DeprecationWarning
+}
+
+func (e *DeprecationError) Error() string {
+	var messages []string
+	for _, warning := range e.Warnings {
+		messages = append(messages, warning.String())
+	}
+	return fmt.Sprintf("configuration sets deprecated keys: %s", strings.Join(messages, "; "))
+}
+
+// findDeprecations finds all the deprecated keys in the schema document.
+func findDeprecations(document map[string]any) ([]deprecation, error) {
+	var deprecations []deprecation
+	if err := walkSchema(document, func(path []string, node map[string]any) {
+		if deprecated, _ := node[deprecatedKeyword].(bool); !deprecated {
+			return
+		}
+		replacedBy, _ := node[replacedByKeyword].(string)
+		deprecations = append(deprecations, deprecation{path: path, replacedBy: replacedBy})
+	}); err != nil {
+		return nil, fmt.Errorf("failed to walk schema: %w", err)
+	}
+	return deprecations, nil
+}
+
+// overrideLayer is one level of overrides that contributes to a resolved configuration.
+type overrideLayer struct {
+	name string
+	cfg  types.Configuration
+}
+
+// deprecationWarnings determines which of the layers set deprecated keys. A key the schema deprecates more than once,
+// like in several branches of an allOf, is only reported once per layer.
+func deprecationWarnings(deprecations []deprecation, layers []overrideLayer) []DeprecationWarning {
+	var warnings []DeprecationWarning
+	type reported struct {
+		level, path string
+	}
+	seen := map[reported]bool{}
+	for _, layer := range layers {
+		for _, d := range deprecations {
+			for _, path := range expandSchemaPath(layer.cfg, d.path) {
+				if seen[reported{level: layer.name, path: path}] {
+					continue
+				}
+				seen[reported{level: layer.name, path: path}] = true
+				warnings = append(warnings, DeprecationWarning{
+					Path:       path,
+					Level:      layer.name,
+					ReplacedBy: d.replacedBy,
+				})
+			}
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,82 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Azure/ARO-Tools/pkg/config"
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+func TestDeprecationWarnings(t *testing.T) {
+	replacements := &config.ConfigReplacements{
+		RegionReplacement:      "uksouth",
+		RegionShortReplacement: "ln",
+		StampReplacement:       "1",
+		CloudReplacement:       "public",
+		EnvironmentReplacement: "int",
+	}
+
+	provider, err := config.NewConfigProvider("testdata/deprecated/config.yaml")
+	require.NoError(t, err)
+	resolver, err := provider.GetResolver(replacements)
+	require.NoError(t, err)
+
+	cfg, warnings, err := resolver.GetRegionConfigurationWithWarnings("uksouth")
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	if diff := cmp.Diff([]config.DeprecationWarning{
+		{Path: "svc.image", Level: "cloud", ReplacedBy: "svc.imageTag"},
+		{Path: "replicaCount", Level: "region"},
+	}, warnings); diff != "" {
+		t.Errorf("warnings mismatch (-want +got):\n%s", diff)
+	}
+
+	_, warnings, err = resolver.GetRegionConfigurationWithWarnings("eastus")
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+
+	strictProvider, err := config.NewConfigProvider("testdata/deprecated/config.yaml", config.WithStrictDeprecations())
+	require.NoError(t, err)
+	strictResolver, err := strictProvider.GetResolver(replacements)
+	require.NoError(t, err)
+
+	_, err = strictResolver.GetRegionConfiguration("uksouth")
+	var deprecationErr *config.DeprecationError
+	require.ErrorAs(t, err, &deprecationErr)
+	require.Len(t, deprecationErr.Warnings, 2)
+	require.EqualError(t, err, "configuration sets deprecated keys: cloud: key svc.image is deprecated, use svc.imageTag instead; region: key replicaCount is deprecated")
+
+	_, err = strictResolver.GetConfiguration()
+	require.ErrorAs(t, err, &deprecationErr)
+	require.Len(t, deprecationErr.Warnings, 1)
+}
+
+func TestDeprecationWarningsAreReportedOnce(t *testing.T) {
+	resolver := uksouthResolver(t, types.Configuration{"svc": map[string]any{"image": "svc:latest"}}, []byte(`{
+  "type": "object",
+  "properties": {"svc": {"allOf": [
+    {"properties": {"image": {"deprecated": true, "x-replacedBy": "svc.imageTag"}}},
+    {"properties": {"image": {"deprecated": true, "x-replacedBy": "svc.imageTag"}}}
+  ]}}
+}`))
+	_, warnings, err := resolver.GetRegionConfigurationWithWarnings("uksouth")
+	require.NoError(t, err)
+	require.Equal(t, []config.DeprecationWarning{{Path: "svc.image", Level: "default", ReplacedBy: "svc.imageTag"}}, warnings)
+}
@@ -0,0 +1,224 @@
+package ev2config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+// CatalogEnvVar names the environment variable holding the path to an external catalog, which is layered over the
+// embedded one by every lookup, so that tools built on this package can resolve clouds and regions the embedded
+// catalog does not have.
+const CatalogEnvVar = "EV2_CONFIG_CATALOG"
+
+// Option configures a lookup in the catalog.
+type Option func(*options)
+
+type options struct {
+	catalogFiles []string
+}
+
+// WithCatalogFile layers the catalog in a file over the embedded one, after the catalog named by CatalogEnvVar, if
+// any. The file has the same format as the embedded catalog. It may add clouds, regions and values, but not change
+// values which are already defined.
+func WithCatalogFile(path string) Option {
+	return func(o *options) {
+		o.catalogFiles = append(o.catalogFiles, path)
+	}
+}
+
+// CatalogConflict records a value an external catalog defines differently than the catalogs below it.
+type CatalogConflict struct {
+	// Path locates the value in dot notation, like clouds.public.regions.uksouth.regionShortName.
+	Path string
+	// Existing is the value already defined, and Value is the one the external catalog defines.
+	Existing, Value any
+}
+
+func (c CatalogConflict) String() string {
+	return fmt.Sprintf("%s: defined as %s, but already defined as %s", c.Path, formatCatalogValue(c.Value), formatCatalogValue(c.Existing))
+}
+
+// CatalogConflictError records every value an external catalog defines differently than the catalogs below it.
+type CatalogConflictError struct {
+	// File is the external catalog.
+	File      string
+	Conflicts []CatalogConflict
+}
+
+func (e *CatalogConflictError) Error() string {
+	var messages []string
+	for _, conflict := range e.Conflicts {
+		messages = append(messages, conflict.String())
+	}
+	return fmt.Sprintf("Ev2 catalog %s conflicts with the catalogs it is layered over:\n%s", e.File, strings.Join(messages, "\n"))
+}
+
+func formatCatalogValue(value any) string {
+	switch value.(type) {
+	case map[string]any, types.Configuration:
+		return "an object"
+	case []any:
+		return "a list"
+	default:
+		return fmt.Sprintf("%#v", value)
+	}
+}
+
+var (
+	catalogLock sync.Mutex
+	// catalogs caches the layered catalogs by the files layered over the embedded one, joined with new lines
+	catalogs = map[string]config{}
+)
+
+// readCatalog returns the embedded catalog with the external catalogs configured by the options layered over it. Each
+// set of external catalogs is read once; callers must not mutate what it returns.
+func readCatalog(opts ...Option) (config, error) {
+	embedded, err := readConfig()
+	if err != nil {
+		return config{}, err
+	}
+	o := &options{}
+	if path := os.Getenv(CatalogEnvVar); path != "" {
+		o.catalogFiles = append(o.catalogFiles, path)
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if len(o.catalogFiles) == 0 {
+		return embedded, nil
+	}
+
+	key := strings.Join(o.catalogFiles, "\n")
+	catalogLock.Lock()
+	defer catalogLock.Unlock()
+	if cached, ok := catalogs[key]; ok {
+		return cached, nil
+	}
+	layered := embedded
+	for _, file := range o.catalogFiles {
+		external, err := readCatalogFile(file)
+		if err != nil {
+			return config{}, err
+		}
+		layered, err = layerCatalog(layered, external, file)
+		if err != nil {
+			return config{}, err
+		}
+	}
+	catalogs[key] = layered
+	return layered, nil
+}
+
+// readCatalogFile parses an external catalog, rejecting fields the embedded catalog doesn't have.
+func readCatalogFile(path string) (config, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return config{}, fmt.Errorf("failed to read Ev2 catalog: %w", err)
+	}
+	var external config
+	if err := yaml.UnmarshalStrict(raw, &external); err != nil {
+		return config{}, fmt.Errorf("failed to parse Ev2 catalog %s: %w", path, err)
+	}
+	for name, cloud := range external.Clouds {
+		for region, values := range cloud.Regions {
+			if values == nil {
+				return config{}, fmt.Errorf("failed to parse Ev2 catalog %s: region %s in cloud %s has no values", path, region, name)
+			}
+		}
+	}
+	return external, nil
+}
+
+// layerCatalog returns a new catalog holding everything in both catalogs, or an error listing every value the external
+// catalog defines differently. Neither catalog is mutated, but the result shares values with both.
+func layerCatalog(base, external config, file string) (config, error) {
+	out := config{Clouds: map[string]SanitizedCloudConfig{}}
+	for name, cloud := range base.Clouds {
+		out.Clouds[name] = cloud
+	}
+
+	var conflicts []CatalogConflict
+	for _, name := range sortedKeys(external.Clouds) {
+		cloud := external.Clouds[name]
+		existing, ok := out.Clouds[name]
+		if !ok {
+			out.Clouds[name] = cloud
+			continue
+		}
+
+		path := "clouds." + name
+		layered := SanitizedCloudConfig{
+			DefaultRegion: existing.DefaultRegion,
+			Defaults:      layerValues(existing.Defaults, cloud.Defaults, path+".defaults", &conflicts),
+			Regions:       map[string]types.Configuration{},
+		}
+		switch {
+		case layered.DefaultRegion == "":
+			layered.DefaultRegion = cloud.DefaultRegion
+		case cloud.DefaultRegion != "" && cloud.DefaultRegion != layered.DefaultRegion:
+			conflicts = append(conflicts, CatalogConflict{Path: path + ".defaultRegion", Existing: layered.DefaultRegion, Value: cloud.DefaultRegion})
+		}
+		for region, values := range existing.Regions {
+			layered.Regions[region] = values
+		}
+		for _, region := range sortedKeys(cloud.Regions) {
+			layered.Regions[region] = layerValues(existing.Regions[region], cloud.Regions[region], path+".regions."+region, &conflicts)
+		}
+		out.Clouds[name] = layered
+	}
+
+	if len(conflicts) > 0 {
+		return config{}, &CatalogConflictError{File: file, Conflicts: conflicts}
+	}
+	for _, name := range sortedKeys(external.Clouds) {
+		cloud := out.Clouds[name]
+		if _, ok := cloud.Regions[cloud.DefaultRegion]; cloud.DefaultRegion != "" && !ok {
+			return config{}, fmt.Errorf("Ev2 catalog %s: default region %s of cloud %s is not one of its regions", file, cloud.DefaultRegion, name)
+		}
+	}
+	return out, nil
+}
+
+// layerValues returns a copy of the base values with the external values added, recording every value the two define
+// differently.
+func layerValues(base, external map[string]any, path string, conflicts *[]CatalogConflict) types.Configuration {
+	out := types.Configuration(base).DeepCopy()
+	if out == nil {
+		out = types.Configuration{}
+	}
+	for _, key := range sortedKeys(external) {
+		value := external[key]
+		keyPath := path + "." + key
+		existing, ok := out[key]
+		if !ok {
+			out[key] = value
+			continue
+		}
+		existingMap, existingIsMap := existing.(map[string]any)
+		valueMap, valueIsMap := value.(map[string]any)
+		switch {
+		case existingIsMap && valueIsMap:
+			out[key] = map[string]any(layerValues(existingMap, valueMap, keyPath, conflicts))
+		case !reflect.DeepEqual(existing, value):
+			*conflicts = append(*conflicts, CatalogConflict{Path: keyPath, Existing: existing, Value: value})
+		}
+	}
+	return out
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -2,6 +2,7 @@ package ev2config
 
 import (
 	"fmt"
+	"sync"
 
 	"sigs.k8s.io/yaml"
 
@@ -14,38 +15,47 @@ import (
 //go:embed config.yaml
 var rawConfig []byte
 
+var (
+	parseConfigOnce sync.Once
+	parsedConfig    config
+	parseConfigErr  error
+)
+
+// readConfig parses the embedded configuration once; callers must not mutate what it returns.
 func readConfig() (config, error) {
-	ev2Config := config{}
-	if err := yaml.Unmarshal(rawConfig, &ev2Config); err != nil {
-		return config{}, fmt.Errorf("failed to parse embedded Ev2 config: %w", err)
-	}
-	return ev2Config, nil
+	parseConfigOnce.Do(func() {
+		if err := yaml.Unmarshal(rawConfig, &parsedConfig); err != nil {
+			parseConfigErr = fmt.Errorf("failed to parse embedded Ev2 config: %w", err)
+		}
+	})
+	return parsedConfig, parseConfigErr
 }
 
-func AllContexts() (map[string][]string, error) {
-	ev2Config, err := readConfig()
+// AllContexts lists the regions of every cloud in the catalog, sorted, with external catalogs layered over the embedded
+// one as the options and CatalogEnvVar configure. Use a Catalog to learn more about the regions.
+func AllContexts(opts ...Option) (map[string][]string, error) {
+	ev2Config, err := readCatalog(opts...)
 	if err != nil {
 		return nil, err
 	}
 	contexts := map[string][]string{}
 	for cloud := range ev2Config.Clouds {
-		contexts[cloud] = []string{}
-		for region := range ev2Config.Clouds[cloud].Regions {
-			contexts[cloud] = append(contexts[cloud], region)
-		}
+		contexts[cloud] = sortedKeys(ev2Config.Clouds[cloud].Regions)
 	}
 	return contexts, nil
 }
 
-func ResolveConfig(cloud, region string) (types.Configuration, error) {
-	ev2Config, err := readConfig()
+// ResolveConfig merges the values for a region over the defaults of its cloud, with external catalogs layered over the
+// embedded one as the options and CatalogEnvVar configure.
+func ResolveConfig(cloud, region string, opts ...Option) (types.Configuration, error) {
+	ev2Config, err := readCatalog(opts...)
 	if err != nil {
 		return nil, err
 	}
 	cfg := types.Configuration{}
 	cloudCfg, hasCloud := ev2Config.Clouds[cloud]
 	if !hasCloud {
-		return nil, fmt.Errorf("failed to find cloud %s", cloud)
+		return nil, fmt.Errorf("failed to find cloud %s, set %s to layer an external catalog defining it", cloud, CatalogEnvVar)
 	}
 	cfg = types.MergeConfiguration(cfg, cloudCfg.Defaults)
 	regionCfg, hasRegion := cloudCfg.Regions[region]
@@ -53,11 +63,13 @@ func ResolveConfig(cloud, region string) (types.Configuration, error) {
 		return nil, fmt.Errorf("failed to find region %s in cloud %s", region, cloud)
 	}
 	cfg = types.MergeConfiguration(cfg, regionCfg)
-	return cfg, nil
+	return types.Configuration(cfg).DeepCopy(), nil
 }
 
-func ResolveConfigForCloud(cloud string) (types.Configuration, error) {
-	ev2Config, err := readConfig()
+// ResolveConfigForCloud resolves the configuration of the default region of a cloud, or of its first region by name if
+// the catalog designates no default, for values which are the same in every region of the cloud.
+func ResolveConfigForCloud(cloud string, opts ...Option) (types.Configuration, error) {
+	ev2Config, err := readCatalog(opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -67,20 +79,15 @@ func ResolveConfigForCloud(cloud string) (types.Configuration, error) {
 		return nil, fmt.Errorf("failed to find cloud %s", cloud)
 	}
 
-	if len(cloudCfg.Regions) == 0 {
-		return nil, fmt.Errorf("no regions available for cloud %s", cloud)
-	}
-
-	// Find the first region
-	var firstRegion string
-	for region := range cloudCfg.Regions {
-		firstRegion = region
-		break
+	region, err := cloudCfg.defaultRegion(cloud)
+	if err != nil {
+		return nil, err
 	}
-
-	return ResolveConfig(cloud, firstRegion)
+	return ResolveConfig(cloud, region, opts...)
 }
 
+// GetDefaultRegionForCloud returns the default region of a rollout cloud, as Catalog.DefaultRegion chooses it; the dev
+// cloud uses the default region of the public cloud.
 func GetDefaultRegionForCloud(cloud cmdutils.RolloutCloud) (string, error) {
 	// Handle dev cloud mapping
 	actualCloud := cloud
@@ -88,19 +95,17 @@ func GetDefaultRegionForCloud(cloud cmdutils.RolloutCloud) (string, error) {
 		actualCloud = cmdutils.RolloutCloudPublic
 	}
 
-	contexts, err := AllContexts()
+	catalog, err := LoadCatalog()
 	if err != nil {
-		return "", fmt.Errorf("failed to get ev2 contexts: %w", err)
+		return "", fmt.Errorf("failed to load Ev2 catalog: %w", err)
 	}
-
-	regions, exists := contexts[string(actualCloud)]
-	if !exists {
+	if _, exists := catalog.config.Clouds[string(actualCloud)]; !exists {
 		return "", fmt.Errorf("unsupported rollout cloud: %s", actualCloud)
 	}
 
-	if len(regions) == 0 {
-		return "", fmt.Errorf("no regions available for cloud %s", actualCloud)
+	region, err := catalog.DefaultRegion(string(actualCloud))
+	if err != nil {
+		return "", err
 	}
-
-	return regions[0], nil
+	return region.Name, nil
 }
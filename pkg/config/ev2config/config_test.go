@@ -1,10 +1,18 @@
 package ev2config
 
 import (
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/Azure/ARO-Tools/pkg/cmdutils"
+	"github.com/Azure/ARO-Tools/pkg/config/types"
 )
 
 func TestRegionShortLowercase(t *testing.T) {
@@ -18,3 +26,230 @@ func TestRegionShortLowercase(t *testing.T) {
 		}
 	}
 }
+
+func TestExternalCatalog(t *testing.T) {
+	_, err := ResolveConfig("usnat", "usnateast")
+	require.ErrorContains(t, err, "failed to find cloud usnat, set EV2_CONFIG_CATALOG to layer an external catalog defining it")
+
+	cfg, err := ResolveConfig("usnat", "usnateast", WithCatalogFile("testdata/usnat.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, types.Configuration{
+		"arm":                   map[string]any{"endpoint": "management.usnat.example"},
+		"cloudName":             "USNat",
+		"keyVault":              map[string]any{"domainNameSuffix": "vault.usnat.example"},
+		"availabilityZoneCount": float64(0),
+		"geoShortId":            "usn",
+		"geography":             "USNat",
+		"regionFriendlyName":    "USNat East",
+		"regionShortName":       "une",
+	}, cfg)
+
+	t.Setenv(CatalogEnvVar, "testdata/usnat.yaml")
+	contexts, err := AllContexts()
+	require.NoError(t, err)
+	require.Equal(t, []string{"usnateast"}, contexts["usnat"])
+	require.Contains(t, contexts["public"], "uksouth")
+
+	uksouth, err := ResolveConfig("public", "uksouth")
+	require.NoError(t, err)
+	require.Equal(t, "ln", uksouth["regionShortName"])
+	require.Equal(t, float64(2), uksouth["stampCount"])
+	require.Equal(t, "vault.azure.net", uksouth["keyVault"].(map[string]any)["domainNameSuffix"])
+
+	embedded, err := readConfig()
+	require.NoError(t, err)
+	require.NotContains(t, embedded.Clouds, "usnat", "layering must not mutate the embedded catalog")
+	require.NotContains(t, embedded.Clouds["public"].Regions["uksouth"], "stampCount", "layering must not mutate the embedded catalog")
+}
+
+func TestExternalCatalogErrors(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	conflicting := write("conflicting.yaml", `clouds:
+  public:
+    defaults:
+      keyVault:
+        domainNameSuffix: vault.example
+    regions:
+      uksouth:
+        regionShortName: uks
+        availabilityZoneCount: 3
+`)
+	_, err := ResolveConfig("public", "uksouth", WithCatalogFile(conflicting))
+	var conflictErr *CatalogConflictError
+	require.ErrorAs(t, err, &conflictErr)
+	require.EqualError(t, err, `Ev2 catalog `+conflicting+` conflicts with the catalogs it is layered over:
+clouds.public.defaults.keyVault.domainNameSuffix: defined as "vault.example", but already defined as "vault.azure.net"
+clouds.public.regions.uksouth.regionShortName: defined as "uks", but already defined as "ln"`)
+
+	// the second catalog conflicts with the first, not with the embedded one
+	first := write("first.yaml", "clouds:\n  usnat:\n    regions:\n      usnateast:\n        regionShortName: une\n")
+	second := write("second.yaml", "clouds:\n  usnat:\n    regions:\n      usnateast:\n        regionShortName:\n          short: une\n")
+	_, err = AllContexts(WithCatalogFile(first), WithCatalogFile(second))
+	require.ErrorContains(t, err, "clouds.usnat.regions.usnateast.regionShortName: defined as an object, but already defined as \"une\"")
+
+	unknown := write("unknown.yaml", "clouds:\n  usnat:\n    region:\n      usnateast: {}\n")
+	_, err = AllContexts(WithCatalogFile(unknown))
+	require.ErrorContains(t, err, "failed to parse Ev2 catalog "+unknown)
+	require.ErrorContains(t, err, `unknown field "region"`)
+
+	empty := write("empty.yaml", "clouds:\n  usnat:\n    regions:\n      usnateast:\n")
+	_, err = AllContexts(WithCatalogFile(empty))
+	require.ErrorContains(t, err, "region usnateast in cloud usnat has no values")
+
+	_, err = AllContexts(WithCatalogFile(filepath.Join(dir, "missing.yaml")))
+	require.ErrorContains(t, err, "failed to read Ev2 catalog")
+}
+
+func TestCatalog(t *testing.T) {
+	catalog, err := LoadCatalog()
+	require.NoError(t, err)
+
+	for _, cloud := range catalog.Clouds() {
+		region, err := catalog.DefaultRegion(cloud)
+		require.NoError(t, err, "cloud %s needs a default region", cloud)
+		require.Equal(t, cloud, region.Cloud)
+	}
+
+	regions, err := catalog.Regions("public", InGeography("united kingdom"), WithAvailabilityZones(3))
+	require.NoError(t, err)
+	require.Equal(t, []RegionInfo{{
+		Cloud: "public",
+		Name:  "uksouth",
+		SanitizedRegionConfig: SanitizedRegionConfig{
+			Geography:             "United Kingdom",
+			GeoShortID:            "uk",
+			AvailabilityZoneCount: 3,
+			RegionShortName:       "ln",
+			RegionFriendlyName:    "UK South",
+		},
+	}}, regions)
+
+	all, err := catalog.Regions("public")
+	require.NoError(t, err)
+	require.True(t, sort.SliceIsSorted(all, func(i, j int) bool { return all[i].Name < all[j].Name }), "regions must be sorted by name")
+
+	contexts, err := AllContexts()
+	require.NoError(t, err)
+	require.Len(t, contexts["public"], len(all))
+	require.True(t, sort.StringsAreSorted(contexts["public"]), "contexts must be sorted")
+
+	uksouth, err := catalog.RegionByShortName("public", "ln")
+	require.NoError(t, err)
+	require.Equal(t, "uksouth", uksouth.Name)
+
+	_, err = catalog.RegionByShortName("public", "zz")
+	require.EqualError(t, err, "failed to find region with short name zz in cloud public")
+	_, err = catalog.Region("public", "moon")
+	require.EqualError(t, err, "failed to find region moon in cloud public")
+	_, err = catalog.Regions("usnat")
+	require.ErrorContains(t, err, "failed to find cloud usnat")
+
+	for cloud, expected := range map[cmdutils.RolloutCloud]string{
+		cmdutils.RolloutCloudPublic:  "eastus",
+		cmdutils.RolloutCloudDev:     "eastus",
+		cmdutils.RolloutCloudFairfax: "usgovvirginia",
+	} {
+		region, err := GetDefaultRegionForCloud(cloud)
+		require.NoError(t, err)
+		require.Equal(t, expected, region, "default region of %s", cloud)
+	}
+
+	cfg, err := ResolveConfigForCloud("public")
+	require.NoError(t, err)
+	require.Equal(t, "East US", cfg["regionFriendlyName"])
+}
+
+func TestCatalogDefaultRegions(t *testing.T) {
+	// a cloud without a designated default region defaults to its first region
+	catalog, err := LoadCatalog(WithCatalogFile("testdata/usnat.yaml"))
+	require.NoError(t, err)
+	region, err := catalog.DefaultRegion("usnat")
+	require.NoError(t, err)
+	require.Equal(t, "usnateast", region.Name)
+	cfg, err := ResolveConfigForCloud("usnat", WithCatalogFile("testdata/usnat.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, "une", cfg["regionShortName"])
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	conflicting := write("conflicting.yaml", "clouds:\n  public:\n    defaultRegion: uksouth\n")
+	_, err = LoadCatalog(WithCatalogFile(conflicting))
+	require.ErrorContains(t, err, `clouds.public.defaultRegion: defined as "uksouth", but already defined as "eastus"`)
+
+	// the default region must be one of the cloud's regions, whether the cloud is new or layered
+	missing := write("missing.yaml", "clouds:\n  usnat:\n    defaultRegion: usnatwest\n")
+	_, err = LoadCatalog(WithCatalogFile("testdata/usnat.yaml"), WithCatalogFile(missing))
+	require.EqualError(t, err, "Ev2 catalog "+missing+": default region usnatwest of cloud usnat is not one of its regions")
+	newCloud := write("new.yaml", "clouds:\n  ussec:\n    defaultRegion: ussecwest\n    regions:\n      usseceast:\n        regionShortName: use\n")
+	_, err = LoadCatalog(WithCatalogFile(newCloud))
+	require.EqualError(t, err, "Ev2 catalog "+newCloud+": default region ussecwest of cloud ussec is not one of its regions")
+}
+
+func TestEmbeddedCatalogMatchesTypes(t *testing.T) {
+	var typed struct {
+		Clouds map[string]struct {
+			DefaultRegion string                           `json:"defaultRegion"`
+			Defaults      SanitizedCloudConfigValues       `json:"defaults"`
+			Regions       map[string]SanitizedRegionConfig `json:"regions"`
+		} `json:"clouds"`
+	}
+	require.NoError(t, yaml.UnmarshalStrict(rawConfig, &typed), "the embedded catalog has values the types lack")
+
+	// every value the types have must be in the catalog, too
+	roundTripped, err := yaml.Marshal(typed)
+	require.NoError(t, err)
+	var expected, actual map[string]any
+	require.NoError(t, yaml.Unmarshal(rawConfig, &expected))
+	require.NoError(t, yaml.Unmarshal(roundTripped, &actual))
+	require.Equal(t, expected, actual, "the types have values the embedded catalog lacks")
+}
+
+func TestTypedLookups(t *testing.T) {
+	public, err := Cloud("public")
+	require.NoError(t, err)
+	require.Equal(t, "vault.azure.net", public.KeyVaultSuffix())
+	require.Equal(t, "azurecr.io", public.ContainerRegistrySuffix())
+	require.Equal(t, "api://AzureADTokenExchange", public.FederatedCredentialAudience())
+	require.Equal(t, "eastus", public.DefaultRegion)
+
+	ff, err := Cloud("ff")
+	require.NoError(t, err)
+	require.Equal(t, "vault.usgovcloudapi.net", ff.KeyVaultSuffix())
+	require.Equal(t, "usgovcloud", ff.AzureTenant().TenantName)
+	require.Equal(t, "usgoveast-dsts.dsts.core.usgovcloudapi.net", ff.GenevaActionsHomeDsts())
+
+	uksouth, err := Region("public", "uksouth")
+	require.NoError(t, err)
+	require.Equal(t, "ln", uksouth.ShortName())
+	require.Equal(t, "UK South", uksouth.FriendlyName())
+
+	usnat, err := Cloud("usnat", WithCatalogFile("testdata/usnat.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, "vault.usnat.example", usnat.KeyVaultSuffix())
+	require.Equal(t, "management.usnat.example", usnat.ARMEndpoint())
+	usnateast, err := Region("usnat", "usnateast", WithCatalogFile("testdata/usnat.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, "une", usnateast.ShortName())
+
+	_, err = Cloud("usnat")
+	require.ErrorContains(t, err, "failed to find cloud usnat")
+	_, err = Region("public", "moon")
+	require.EqualError(t, err, "failed to find region moon in cloud public")
+
+	dir := t.TempDir()
+	mistyped := filepath.Join(dir, "mistyped.yaml")
+	require.NoError(t, os.WriteFile(mistyped, []byte("clouds:\n  usnat:\n    defaults:\n      keyVault:\n        domainNameSuffix: 42\n    regions:\n      usnateast:\n        regionShortName: une\n"), 0644))
+	_, err = Cloud("usnat", WithCatalogFile(mistyped))
+	require.ErrorContains(t, err, "failed to decode defaults of cloud usnat")
+}
@@ -0,0 +1,195 @@
+package ev2config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+// CloudInfo describes a cloud in the Ev2 catalog, with the values from the defaults of the cloud.
+type CloudInfo struct {
+	Name string
+	// DefaultRegion is the region the catalog designates to use when any region of the cloud will do, if any. See
+	// Catalog.DefaultRegion for the region to use when it designates none.
+	DefaultRegion string
+
+	SanitizedCloudConfigValues
+}
+
+// RegionInfo describes a region in the Ev2 catalog.
+type RegionInfo struct {
+	Cloud string `json:"-"`
+	Name  string `json:"-"`
+
+	SanitizedRegionConfig
+}
+
+// Cloud looks up a cloud in the catalog by name, with external catalogs layered over the embedded one as the options
+// and CatalogEnvVar configure. As for Catalog.Cloud, only the defaults of the cloud are decoded.
+func Cloud(name string, opts ...Option) (CloudInfo, error) {
+	catalog, err := LoadCatalog(opts...)
+	if err != nil {
+		return CloudInfo{}, err
+	}
+	return catalog.Cloud(name)
+}
+
+// Region looks up a region of a cloud in the catalog by name, with external catalogs layered over the embedded one as
+// the options and CatalogEnvVar configure.
+func Region(cloud, name string, opts ...Option) (RegionInfo, error) {
+	catalog, err := LoadCatalog(opts...)
+	if err != nil {
+		return RegionInfo{}, err
+	}
+	return catalog.Region(cloud, name)
+}
+
+// RegionFilter selects regions when listing them.
+type RegionFilter func(RegionInfo) bool
+
+// InGeography selects the regions in a geography, like "United States", ignoring case.
+func InGeography(geography string) RegionFilter {
+	return func(region RegionInfo) bool {
+		return strings.EqualFold(region.Geography, geography)
+	}
+}
+
+// WithAvailabilityZones selects the regions with at least the given number of availability zones.
+func WithAvailabilityZones(minimum int) RegionFilter {
+	return func(region RegionInfo) bool {
+		return region.AvailabilityZoneCount >= minimum
+	}
+}
+
+// Catalog answers questions about the clouds and regions in the Ev2 catalog, with external catalogs layered over the
+// embedded one as the options and CatalogEnvVar configure.
+type Catalog struct {
+	config config
+}
+
+// LoadCatalog loads the Ev2 catalog.
+func LoadCatalog(opts ...Option) (*Catalog, error) {
+	cfg, err := readCatalog(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Catalog{config: cfg}, nil
+}
+
+// Clouds lists the clouds in the catalog, sorted.
+func (c *Catalog) Clouds() []string {
+	return sortedKeys(c.config.Clouds)
+}
+
+func (c *Catalog) cloud(name string) (SanitizedCloudConfig, error) {
+	cloud, ok := c.config.Clouds[name]
+	if !ok {
+		return SanitizedCloudConfig{}, fmt.Errorf("failed to find cloud %s, set %s to layer an external catalog defining it", name, CatalogEnvVar)
+	}
+	return cloud, nil
+}
+
+// Cloud looks up a cloud by name. Only the defaults of the cloud are decoded: a value a region of the cloud overrides
+// is not reflected, so use ResolveConfig for the values of a particular region.
+func (c *Catalog) Cloud(name string) (CloudInfo, error) {
+	cloudCfg, err := c.cloud(name)
+	if err != nil {
+		return CloudInfo{}, err
+	}
+	cloud := CloudInfo{Name: name, DefaultRegion: cloudCfg.DefaultRegion}
+	if err := types.Decode(cloudCfg.Defaults, &cloud.SanitizedCloudConfigValues); err != nil {
+		return CloudInfo{}, fmt.Errorf("failed to decode defaults of cloud %s: %w", name, err)
+	}
+	return cloud, nil
+}
+
+func regionInfo(cloud, name string, values types.Configuration) (RegionInfo, error) {
+	var region RegionInfo
+	if err := types.Decode(values, &region); err != nil {
+		return RegionInfo{}, fmt.Errorf("failed to decode region %s in cloud %s: %w", name, cloud, err)
+	}
+	region.Cloud, region.Name = cloud, name
+	return region, nil
+}
+
+// Regions lists the regions of a cloud which every filter selects, sorted by name.
+func (c *Catalog) Regions(cloud string, filters ...RegionFilter) ([]RegionInfo, error) {
+	cloudCfg, err := c.cloud(cloud)
+	if err != nil {
+		return nil, err
+	}
+	var regions []RegionInfo
+	for _, name := range sortedKeys(cloudCfg.Regions) {
+		region, err := regionInfo(cloud, name, cloudCfg.Regions[name])
+		if err != nil {
+			return nil, err
+		}
+		selected := true
+		for _, filter := range filters {
+			if !filter(region) {
+				selected = false
+				break
+			}
+		}
+		if selected {
+			regions = append(regions, region)
+		}
+	}
+	return regions, nil
+}
+
+// Region looks up a region of a cloud by name.
+func (c *Catalog) Region(cloud, name string) (RegionInfo, error) {
+	cloudCfg, err := c.cloud(cloud)
+	if err != nil {
+		return RegionInfo{}, err
+	}
+	values, ok := cloudCfg.Regions[name]
+	if !ok {
+		return RegionInfo{}, fmt.Errorf("failed to find region %s in cloud %s", name, cloud)
+	}
+	return regionInfo(cloud, name, values)
+}
+
+// RegionByShortName looks up a region of a cloud by its short name, like "ln" for uksouth.
+func (c *Catalog) RegionByShortName(cloud, shortName string) (RegionInfo, error) {
+	regions, err := c.Regions(cloud)
+	if err != nil {
+		return RegionInfo{}, err
+	}
+	var matches []RegionInfo
+	for _, region := range regions {
+		if region.ShortName() == shortName {
+			matches = append(matches, region)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return RegionInfo{}, fmt.Errorf("failed to find region with short name %s in cloud %s", shortName, cloud)
+	case 1:
+		return matches[0], nil
+	default:
+		names := make([]string, 0, len(matches))
+		for _, match := range matches {
+			names = append(names, match.Name)
+		}
+		sort.Strings(names)
+		return RegionInfo{}, fmt.Errorf("short name %s is used by several regions in cloud %s: %s", shortName, cloud, strings.Join(names, ", "))
+	}
+}
+
+// DefaultRegion returns the region designated as the default for a cloud, or its first region by name if the catalog
+// designates no default, for when any region of the cloud will do.
+func (c *Catalog) DefaultRegion(cloud string) (RegionInfo, error) {
+	cloudCfg, err := c.cloud(cloud)
+	if err != nil {
+		return RegionInfo{}, err
+	}
+	name, err := cloudCfg.defaultRegion(cloud)
+	if err != nil {
+		return RegionInfo{}, err
+	}
+	return c.Region(cloud, name)
+}
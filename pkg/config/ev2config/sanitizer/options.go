@@ -20,6 +20,7 @@ func DefaultOptions() *RawOptions {
 func BindOptions(opts *RawOptions, cmd *cobra.Command) error {
 	cmd.Flags().StringArrayVar(&opts.Ev2Configurations, "input", opts.Ev2Configurations, "Path to an input Ev2 central configuration file.")
 	cmd.Flags().StringVar(&opts.OutputFile, "output", opts.OutputFile, "File to write output configuration to.")
+	cmd.Flags().StringToStringVar(&opts.DefaultRegions, "default-region", opts.DefaultRegions, "Region to designate as the default for a cloud, as cloud=region, for every cloud.")
 
 	for _, flag := range []string{"input", "output"} {
 		if err := cmd.MarkFlagFilename(flag); err != nil {
@@ -33,6 +34,7 @@ func BindOptions(opts *RawOptions, cmd *cobra.Command) error {
 type RawOptions struct {
 	Ev2Configurations []string
 	OutputFile        string
+	DefaultRegions    map[string]string
 }
 
 // validatedOptions is a private wrapper that enforces a call of Validate() before Complete() can be invoked.
@@ -47,8 +49,9 @@ type ValidatedOptions struct {
 
 // completedOptions is a private wrapper that enforces a call of Complete() before config generation can be invoked.
 type completedOptions struct {
-	ConfigByCloud map[string]CentralConfig
-	Output        io.WriteCloser
+	ConfigByCloud  map[string]CentralConfig
+	DefaultRegions map[string]string
+	Output         io.WriteCloser
 }
 
 type Options struct {
@@ -100,21 +103,25 @@ func (o *ValidatedOptions) Complete() (*Options, error) {
 
 	return &Options{
 		completedOptions: &completedOptions{
-			ConfigByCloud: configByCloud,
-			Output:        output,
+			ConfigByCloud:  configByCloud,
+			DefaultRegions: o.DefaultRegions,
+			Output:         output,
 		},
 	}, nil
 }
 
 func (opts *Options) Sanitize() error {
-	output := Sanitize(opts.ConfigByCloud)
-
 	defer func() {
 		if err := opts.Output.Close(); err != nil {
 			slog.Error("failed to close output file", "error", err)
 		}
 	}()
 
+	output, err := Sanitize(opts.ConfigByCloud, opts.DefaultRegions)
+	if err != nil {
+		return fmt.Errorf("failed to sanitize configuration: %w", err)
+	}
+
 	encoded, err := yaml.Marshal(output)
 	if err != nil {
 		return fmt.Errorf("failed to marshal output: %w", err)
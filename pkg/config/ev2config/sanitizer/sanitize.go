@@ -1,19 +1,22 @@
 package main
 
-const (
-	AzureTenantName              = "azure"
-	GenevaActionsHomeDstsPrimary = "primary"
+import (
+	"fmt"
+
+	"github.com/Azure/ARO-Tools/pkg/config/ev2config"
 )
 
-func Sanitize(inputs map[string]CentralConfig) SanitizedConfig {
+// Sanitize strips the central configuration of every cloud down to the values we need, recording the default region
+// designated for each cloud, which must be one of its regions.
+func Sanitize(inputs map[string]CentralConfig, defaultRegions map[string]string) (SanitizedConfig, error) {
 	output := SanitizedConfig{
 		Clouds: map[string]SanitizedCloudConfig{},
 	}
 	for cloud, cfg := range inputs {
-		regions := map[string]SanitizedRegionConfig{}
+		regions := map[string]ev2config.SanitizedRegionConfig{}
 		for _, geo := range cfg.Geographies {
 			for _, region := range geo.Regions {
-				regions[region.Name] = SanitizedRegionConfig{
+				regions[region.Name] = ev2config.SanitizedRegionConfig{
 					Geography:             geo.Name,
 					GeoShortID:            geo.Settings.GeoShortID,
 					AvailabilityZoneCount: region.Settings.AvailabilityZoneCount,
@@ -22,31 +25,39 @@ func Sanitize(inputs map[string]CentralConfig) SanitizedConfig {
 				}
 			}
 		}
+		defaultRegion, ok := defaultRegions[cloud]
+		if !ok {
+			return SanitizedConfig{}, fmt.Errorf("no default region designated for cloud %s", cloud)
+		}
+		if _, ok := regions[defaultRegion]; !ok {
+			return SanitizedConfig{}, fmt.Errorf("default region %s is not a region of cloud %s", defaultRegion, cloud)
+		}
 		output.Clouds[cloud] = SanitizedCloudConfig{
-			Defaults: SanitizedCloudConfigValues{
+			DefaultRegion: defaultRegion,
+			Defaults: ev2config.SanitizedCloudConfigValues{
 				CloudName: cfg.Settings.CloudName,
-				KeyVault: KeyVaultValues{
+				KeyVault: ev2config.KeyVaultValues{
 					DomainNameSuffix: cfg.Settings.KeyVault.DomainNameSuffix,
 				},
-				AzureContainerRegistry: AzureContainerRegistryValues{
+				AzureContainerRegistry: ev2config.AzureContainerRegistryValues{
 					DomainNameSuffix: cfg.Settings.AzureContainerRegistry.DomainNameSuffix,
 				},
-				Entra: SanitizedEntraConfig{
-					FederatedCredentials: EntraFederatedCredentials{
+				Entra: ev2config.SanitizedEntraConfig{
+					FederatedCredentials: ev2config.EntraFederatedCredentialValues{
 						Audience: cfg.Settings.Entra.FederatedCredentials.Audience,
 					},
 					FQDN: cfg.Settings.Entra.FQDN,
-					Tenants: map[string]EntraTenant{
-						AzureTenantName: cfg.Settings.Entra.Tenants[AzureTenantName],
+					Tenants: map[string]ev2config.EntraTenant{
+						ev2config.AzureTenantName: ev2config.EntraTenant(cfg.Settings.Entra.Tenants[ev2config.AzureTenantName]),
 					},
 				},
-				ARM: SanitizedARMConfig{
+				ARM: ev2config.SanitizedARMConfig{
 					Endpoint: cfg.Settings.ARM.Endpoint,
 				},
-				Geneva: SanitizedGenevaConfig{
-					Actions: SanitizedGenevaActionsConfig{
+				Geneva: ev2config.SanitizedGenevaConfig{
+					Actions: ev2config.SanitizedGenevaActionsConfig{
 						HomeDsts: map[string]string{
-							GenevaActionsHomeDstsPrimary: cfg.Settings.Geneva.Actions.HomeDsts[GenevaActionsHomeDstsPrimary],
+							ev2config.GenevaActionsHomeDstsPrimary: cfg.Settings.Geneva.Actions.HomeDsts[ev2config.GenevaActionsHomeDstsPrimary],
 						},
 					},
 				},
@@ -54,5 +65,10 @@ func Sanitize(inputs map[string]CentralConfig) SanitizedConfig {
 			Regions: regions,
 		}
 	}
-	return output
+	for cloud := range defaultRegions {
+		if _, ok := inputs[cloud]; !ok {
+			return SanitizedConfig{}, fmt.Errorf("default region designated for cloud %s, which has no input", cloud)
+		}
+	}
+	return output, nil
 }
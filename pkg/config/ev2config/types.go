@@ -1,6 +1,8 @@
 package ev2config
 
 import (
+	"fmt"
+
 	"github.com/Azure/ARO-Tools/pkg/config/types"
 )
 
@@ -9,6 +11,19 @@ type config struct {
 }
 
 type SanitizedCloudConfig struct {
-	Defaults types.Configuration            `json:"defaults"`
-	Regions  map[string]types.Configuration `json:"regions"`
+	// DefaultRegion is the region to use when any region of the cloud will do.
+	DefaultRegion string                         `json:"defaultRegion,omitempty"`
+	Defaults      types.Configuration            `json:"defaults"`
+	Regions       map[string]types.Configuration `json:"regions"`
+}
+
+// defaultRegion returns the designated default region of the cloud, or its first region by name if none is designated.
+func (c SanitizedCloudConfig) defaultRegion(cloud string) (string, error) {
+	if c.DefaultRegion != "" {
+		return c.DefaultRegion, nil
+	}
+	if len(c.Regions) == 0 {
+		return "", fmt.Errorf("no regions available for cloud %s", cloud)
+	}
+	return sortedKeys(c.Regions)[0], nil
 }
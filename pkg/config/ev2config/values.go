@@ -0,0 +1,104 @@
+package ev2config
+
+// SanitizedCloudConfigValues holds the values every region of a cloud shares, as the sanitizer writes them to the
+// defaults of each cloud in the catalog.
+type SanitizedCloudConfigValues struct {
+	CloudName              string                       `json:"cloudName"`
+	KeyVault               KeyVaultValues               `json:"keyVault"`
+	AzureContainerRegistry AzureContainerRegistryValues `json:"azureContainerRegistry"`
+	Entra                  SanitizedEntraConfig         `json:"entra"`
+	ARM                    SanitizedARMConfig           `json:"arm"`
+	Geneva                 SanitizedGenevaConfig        `json:"geneva"`
+}
+
+type KeyVaultValues struct {
+	DomainNameSuffix string `json:"domainNameSuffix"`
+}
+
+type AzureContainerRegistryValues struct {
+	DomainNameSuffix string `json:"domainNameSuffix"`
+}
+
+type SanitizedEntraConfig struct {
+	FederatedCredentials EntraFederatedCredentialValues `json:"federatedcredentials"`
+	FQDN                 map[string]string              `json:"fqdn"`
+	Tenants              map[string]EntraTenant         `json:"tenants"`
+}
+
+type EntraFederatedCredentialValues struct {
+	Audience string `json:"audience"`
+}
+
+type EntraTenant struct {
+	TenantDomain string `json:"tenantdomain"`
+	TenantID     string `json:"tenantid"`
+	TenantName   string `json:"tenantname"`
+}
+
+type SanitizedARMConfig struct {
+	Endpoint string `json:"endpoint"`
+}
+
+type SanitizedGenevaConfig struct {
+	Actions SanitizedGenevaActionsConfig `json:"actions"`
+}
+
+type SanitizedGenevaActionsConfig struct {
+	HomeDsts map[string]string `json:"homeDsts"`
+}
+
+// SanitizedRegionConfig holds the values of one region of a cloud, as the sanitizer writes them to the catalog.
+type SanitizedRegionConfig struct {
+	Geography             string `json:"geography"`
+	GeoShortID            string `json:"geoShortId"`
+	AvailabilityZoneCount int    `json:"availabilityZoneCount"`
+	RegionShortName       string `json:"regionShortName"`
+	RegionFriendlyName    string `json:"regionFriendlyName"`
+}
+
+const (
+	// AzureTenantName names the Entra tenant the sanitizer keeps for every cloud.
+	AzureTenantName = "azure"
+	// GenevaActionsHomeDstsPrimary names the Geneva Actions home dSTS the sanitizer keeps for every cloud.
+	GenevaActionsHomeDstsPrimary = "primary"
+)
+
+// KeyVaultSuffix is the domain name suffix of Key Vaults in the cloud, like vault.azure.net.
+func (v SanitizedCloudConfigValues) KeyVaultSuffix() string {
+	return v.KeyVault.DomainNameSuffix
+}
+
+// ContainerRegistrySuffix is the domain name suffix of container registries in the cloud, like azurecr.io.
+func (v SanitizedCloudConfigValues) ContainerRegistrySuffix() string {
+	return v.AzureContainerRegistry.DomainNameSuffix
+}
+
+// ARMEndpoint is the Azure Resource Manager endpoint of the cloud.
+func (v SanitizedCloudConfigValues) ARMEndpoint() string {
+	return v.ARM.Endpoint
+}
+
+// FederatedCredentialAudience is the audience of tokens exchanged for Entra federated credentials in the cloud.
+func (v SanitizedCloudConfigValues) FederatedCredentialAudience() string {
+	return v.Entra.FederatedCredentials.Audience
+}
+
+// AzureTenant is the Entra tenant of the cloud.
+func (v SanitizedCloudConfigValues) AzureTenant() EntraTenant {
+	return v.Entra.Tenants[AzureTenantName]
+}
+
+// GenevaActionsHomeDsts is the primary home dSTS of Geneva Actions in the cloud.
+func (v SanitizedCloudConfigValues) GenevaActionsHomeDsts() string {
+	return v.Geneva.Actions.HomeDsts[GenevaActionsHomeDstsPrimary]
+}
+
+// ShortName is the short name of the region, like ln for uksouth.
+func (r SanitizedRegionConfig) ShortName() string {
+	return r.RegionShortName
+}
+
+// FriendlyName is the display name of the region, like UK South.
+func (r SanitizedRegionConfig) FriendlyName() string {
+	return r.RegionFriendlyName
+}
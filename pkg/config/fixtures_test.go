@@ -0,0 +1,40 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config_test
+
+import (
+	"testing"
+
+	"github.com/Azure/ARO-Tools/pkg/config"
+	"github.com/Azure/ARO-Tools/pkg/config/configtest"
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+// uksouthResolver builds a provider holding the defaults for a single public/int/uksouth context, validated against the
+// schema, and returns the resolver for that context.
+func uksouthResolver(t *testing.T, defaults types.Configuration, schema []byte) config.ConfigResolver {
+	t.Helper()
+	provider := configtest.NewBuilder().
+		WithDefaults(defaults).
+		WithRegion("public", "int", "uksouth", nil).
+		WithSchema(schema).
+		MustBuild(t)
+	return configtest.Resolver(t, provider, "public", "int", "uksouth")
+}
+
+// wrappedResolver stands in for resolvers implemented outside of the config package.
+type wrappedResolver struct {
+	config.ConfigResolver
+}
@@ -0,0 +1,187 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"sigs.k8s.io/yaml"
+
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+// Invariant is a constraint relating several configuration values, which JSONSchema cannot express. The expression is
+// written in CEL, must evaluate to a boolean, and has access to the resolved configuration as `config` and to the
+// context it was resolved for as `ctx`, with the same fields as are available to configuration templates.
+type Invariant struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+	Message    string `json:"message"`
+}
+
+// InvariantsFile holds the invariants for a configuration file.
+type InvariantsFile struct {
+	Invariants []Invariant `json:"invariants"`
+}
+
+// LoadInvariants reads an invariants file.
+func LoadInvariants(path string) ([]Invariant, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read invariants file %s: %w", path, err)
+	}
+	var file InvariantsFile
+	if err := yaml.UnmarshalStrict(raw, &file); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal invariants file %s: %w", path, err)
+	}
+	return file.Invariants, nil
+}
+
+// InvariantChecker evaluates compiled invariants.
+type InvariantChecker struct {
+	invariants []Invariant
+	programs   []cel.Program
+}
+
+// NewInvariantChecker compiles the invariants, failing if any expression is invalid or does not produce a boolean.
+func NewInvariantChecker(invariants []Invariant) (*InvariantChecker, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("config", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("ctx", cel.MapType(cel.StringType, cel.DynType)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+
+	checker := &InvariantChecker{invariants: invariants}
+	var errs []error
+	for _, invariant := range invariants {
+		ast, issues := env.Compile(invariant.Expression)
+		if issues != nil && issues.Err() != nil {
+			errs = append(errs, fmt.Errorf("invariant %s: failed to compile: %w", invariant.Name, issues.Err()))
+			continue
+		}
+		if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+			errs = append(errs, fmt.Errorf("invariant %s: expression must evaluate to a bool, not %s", invariant.Name, ast.OutputType()))
+			continue
+		}
+		program, err := env.Program(ast)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invariant %s: failed to create program: %w", invariant.Name, err))
+			continue
+		}
+		checker.programs = append(checker.programs, program)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return checker, nil
+}
+
+// InvariantViolation records an invariant that does not hold for a context.
+type InvariantViolation struct {
+	Cloud       string `json:"cloud"`
+	Environment string `json:"environment"`
+	Region      string `json:"region"`
+	Invariant   string `json:"invariant"`
+	Message     string `json:"message"`
+	// Error is set when the expression could not be evaluated, for instance as it refers to a key that is not set.
+	Error string `json:"error,omitempty"`
+}
+
+func (v InvariantViolation) String() string {
+	msg := fmt.Sprintf("%s/%s/%s: %s: %s", v.Cloud, v.Environment, v.Region, v.Invariant, v.Message)
+	if v.Error != "" {
+		msg += fmt.Sprintf(" (%s)", v.Error)
+	}
+	return msg
+}
+
+// InvariantError records every invariant violation found across contexts.
+type InvariantError struct {
+	Violations []InvariantViolation `json:"violations"`
+}
+
+func (e *InvariantError) Error() string {
+	var messages []string
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.String())
+	}
+	return fmt.Sprintf("%d configuration invariants violated:\n%s", len(e.Violations), strings.Join(messages, "\n"))
+}
+
+// Check evaluates every invariant against the configuration resolved for a context.
+func (c *InvariantChecker) Check(cfg types.Configuration, replacements *ConfigReplacements) []InvariantViolation {
+	activation := map[string]any{
+		"config": map[string]any(cfg),
+		"ctx":    replacements.AsMap()["ctx"],
+	}
+	var violations []InvariantViolation
+	for i, program := range c.programs {
+		violation := InvariantViolation{
+			Cloud:       replacements.CloudReplacement,
+			Environment: replacements.EnvironmentReplacement,
+			Region:      replacements.RegionReplacement,
+			Invariant:   c.invariants[i].Name,
+			Message:     c.invariants[i].Message,
+		}
+		out, _, err := program.Eval(activation)
+		if err != nil {
+			violation.Error = fmt.Sprintf("failed to evaluate: %v", err)
+			violations = append(violations, violation)
+			continue
+		}
+		holds, ok := out.Value().(bool)
+		if !ok {
+			violation.Error = fmt.Sprintf("expression evaluated to %T, not a bool", out.Value())
+			violations = append(violations, violation)
+			continue
+		}
+		if !holds {
+			violations = append(violations, violation)
+		}
+	}
+	return violations
+}
+
+// CheckContexts evaluates every invariant against every resolved context. An *InvariantError lists every violation.
+func (c *InvariantChecker) CheckContexts(contexts []ResolvedContext) error {
+	invariantErr := &InvariantError{}
+	for _, ctx := range contexts {
+		invariantErr.Violations = append(invariantErr.Violations, c.Check(ctx.Configuration, ctx.Replacements)...)
+	}
+	if len(invariantErr.Violations) > 0 {
+		return invariantErr
+	}
+	return nil
+}
+
+// CheckInvariants evaluates every invariant against every context the provider has explicit records for. An
+// *InvariantError lists every violation.
+func CheckInvariants(provider ConfigProvider, invariants []Invariant) error {
+	checker, err := NewInvariantChecker(invariants)
+	if err != nil {
+		return err
+	}
+	contexts, err := ResolveAllContexts(provider)
+	if err != nil {
+		return err
+	}
+	return checker.CheckContexts(contexts)
+}
@@ -0,0 +1,51 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Azure/ARO-Tools/internal/testutil"
+	"github.com/Azure/ARO-Tools/pkg/config"
+	"github.com/Azure/ARO-Tools/pkg/config/configtest"
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+func TestCheckInvariants(t *testing.T) {
+	invariants, err := config.LoadInvariants("testdata/invariants/invariants.yaml")
+	require.NoError(t, err)
+
+	provider := configtest.NewBuilder().
+		WithDefaults(types.Configuration{"regionRG": "hcp-{{ .ctx.region }}", "sku": "Standard_LRS", "replicas": 3}).
+		WithEnvironment("public", "int", nil).
+		WithRegion("public", "int", "uksouth", nil).
+		WithRegion("public", "int", "eastus", types.Configuration{"regionRG": "hcp-shared"}).
+		WithEnvironment("public", "prod", types.Configuration{"sku": "Standard_ZRS"}).
+		WithRegion("public", "prod", "uksouth", nil).
+		WithRegion("public", "prod", "westus", types.Configuration{"sku": "Standard_LRS", "replicas": 1}).
+		WithRegion("public", "prod", "westeurope", types.Configuration{"replicas": nil}).
+		MustBuild(t)
+
+	err = config.CheckInvariants(provider, invariants)
+	var invariantErr *config.InvariantError
+	require.ErrorAs(t, err, &invariantErr)
+	testutil.CompareWithFixture(t, invariantErr)
+
+	_, err = config.NewInvariantChecker([]config.Invariant{{Name: "not-bool", Expression: `"value"`}, {Name: "invalid", Expression: "config.("}})
+	require.ErrorContains(t, err, "invariant not-bool: expression must evaluate to a bool")
+	require.ErrorContains(t, err, "invariant invalid: failed to compile")
+}
@@ -0,0 +1,174 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/Azure/ARO-Tools/pkg/yamlwrap"
+)
+
+// migration rewrites the document of a raw configuration file from one version of the meta-schema to the next.
+type migration struct {
+	from, to string
+	migrate  func(root *yaml.Node) error
+}
+
+var migrations = []migration{
+	{from: metaSchemaV1Ref, to: metaSchemaV2Ref, migrate: migrateV1ToV2},
+}
+
+// MigrateConfiguration rewrites a raw configuration file to the next version of the meta-schema, returning the new
+// content and the version it now uses. The file is not pre-processed; template expressions are wrapped with yamlwrap
+// before the file is parsed and unwrapped afterward, so they are preserved verbatim, as are comments.
+func MigrateConfiguration(raw []byte) ([]byte, string, error) {
+	wrapped, err := yamlwrap.WrapYAML(raw, true)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to wrap configuration: %w", err)
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(wrapped, &document); err != nil {
+		return nil, "", fmt.Errorf("failed to parse configuration: %w", err)
+	}
+	if document.Kind != yaml.DocumentNode || len(document.Content) != 1 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, "", fmt.Errorf("configuration must be a mapping")
+	}
+	root := document.Content[0]
+
+	current := defaultMetaSchemaRef
+	if value := mappingValue(root, "$metaSchema"); value != nil {
+		current = value.Value
+	}
+	if current == LatestMetaSchema {
+		return nil, "", fmt.Errorf("configuration already uses the latest meta schema %s", LatestMetaSchema)
+	}
+
+	var next *migration
+	for i := range migrations {
+		if migrations[i].from == current {
+			next = &migrations[i]
+			break
+		}
+	}
+	if next == nil {
+		return nil, "", fmt.Errorf("no migration registered from meta schema %s", current)
+	}
+
+	if err := next.migrate(root); err != nil {
+		return nil, "", fmt.Errorf("failed to migrate from %s to %s: %w", next.from, next.to, err)
+	}
+	setMappingValue(root, "$metaSchema", next.to)
+
+	out := bytes.Buffer{}
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, "", fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to encode configuration: %w", err)
+	}
+
+	unwrapped, err := yamlwrap.UnwrapYAML(out.Bytes())
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to unwrap configuration: %w", err)
+	}
+	return unwrapped, next.to, nil
+}
+
+// migrateV1ToV2 nests the overrides for each region under a defaults key, making room for stamps.
+func migrateV1ToV2(root *yaml.Node) error {
+	clouds := mappingValue(root, "clouds")
+	if clouds == nil {
+		return nil
+	}
+	for _, cloud := range mappingValues(clouds) {
+		environments := mappingValue(cloud, "environments")
+		if environments == nil {
+			continue
+		}
+		for _, environment := range mappingValues(environments) {
+			regions := mappingValue(environment, "regions")
+			if regions == nil {
+				continue
+			}
+			for i := 1; i < len(regions.Content); i += 2 {
+				overrides := regions.Content[i]
+				if isNull(overrides) {
+					overrides = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Style: yaml.FlowStyle}
+				}
+				if overrides.Kind != yaml.MappingNode {
+					return fmt.Errorf("region %s: expected a mapping of overrides", regions.Content[i-1].Value)
+				}
+				regions.Content[i] = &yaml.Node{
+					Kind: yaml.MappingNode,
+					Tag:  "!!map",
+					Content: []*yaml.Node{
+						{Kind: yaml.ScalarNode, Tag: "!!str", Value: "defaults"},
+						overrides,
+					},
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// mappingValue returns the value for a key in a mapping node, if the node is a mapping and the key is present.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// mappingValues returns all the values in a mapping node.
+func mappingValues(node *yaml.Node) []*yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	var values []*yaml.Node
+	for i := 1; i < len(node.Content); i += 2 {
+		values = append(values, node.Content[i])
+	}
+	return values
+}
+
+// setMappingValue sets a scalar value for a key in a mapping node, adding the key at the top if it is not present.
+func setMappingValue(node *yaml.Node, key, value string) {
+	if existing := mappingValue(node, key); existing != nil {
+		existing.Value = value
+		return
+	}
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+	if len(node.Content) > 0 {
+		// keep comments at the top of the file above the new key
+		keyNode.HeadComment, node.Content[0].HeadComment = node.Content[0].HeadComment, ""
+	}
+	node.Content = append([]*yaml.Node{keyNode, {Kind: yaml.ScalarNode, Tag: "!!str", Value: value}}, node.Content...)
+}
+
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
@@ -0,0 +1,245 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+// resourceTypeKeyword marks a property in the service schema as holding the name of an Azure resource of some type,
+// which must then follow the naming rules for that type.
+const resourceTypeKeyword = "x-azure-resource-type"
+
+// namingRule encodes the restrictions Azure places on names of a type of resource.
+// See https://learn.microsoft.com/en-us/azure/azure-resource-manager/management/resource-name-rules
+type namingRule struct {
+	minLength, maxLength int
+
+	// characters matches every character allowed in the name, and is described by charactersDescription
+	characters            *regexp.Regexp
+	charactersDescription string
+	// start and end, if set, match the characters allowed at the start and end of the name
+	start, end                       *regexp.Regexp
+	startDescription, endDescription string
+
+	noConsecutiveHyphens bool
+}
+
+var (
+	alphanumerics                  = regexp.MustCompile(`^[a-zA-Z0-9]+$`)
+	lowercaseAlphanumerics         = regexp.MustCompile(`^[a-z0-9]+$`)
+	alphanumericsAndHyphens        = regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
+	lowercaseAlphanumericsHyphens  = regexp.MustCompile(`^[a-z0-9-]+$`)
+	alphanumericsUnderscoresHyphen = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+	alphanumericsPeriodsEtc        = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+	resourceGroupCharacters        = regexp.MustCompile(`^[a-zA-Z0-9_.()-]+$`)
+
+	letter                  = regexp.MustCompile(`^[a-zA-Z]`)
+	lowercaseLetter         = regexp.MustCompile(`^[a-z]`)
+	alphanumericStart       = regexp.MustCompile(`^[a-zA-Z0-9]`)
+	alphanumericEnd         = regexp.MustCompile(`[a-zA-Z0-9]$`)
+	alphanumericUnderscores = regexp.MustCompile(`[a-zA-Z0-9_]$`)
+	notPeriod               = regexp.MustCompile(`[^.]$`)
+)
+
+// namingRules holds the rules for the resource types that our pipelines create.
+var namingRules = map[string]namingRule{
+	"Microsoft.KeyVault/vaults": {
+		minLength: 3, maxLength: 24,
+		characters: alphanumericsAndHyphens, charactersDescription: "alphanumerics and hyphens",
+		start: letter, startDescription: "a letter",
+		end: alphanumericEnd, endDescription: "a letter or digit",
+		noConsecutiveHyphens: true,
+	},
+	"Microsoft.Storage/storageAccounts": {
+		minLength: 3, maxLength: 24,
+		characters: lowercaseAlphanumerics, charactersDescription: "lowercase letters and numbers",
+	},
+	"Microsoft.ContainerRegistry/registries": {
+		minLength: 5, maxLength: 50,
+		characters: alphanumerics, charactersDescription: "alphanumerics",
+	},
+	"Microsoft.Resources/resourceGroups": {
+		minLength: 1, maxLength: 90,
+		characters: resourceGroupCharacters, charactersDescription: "alphanumerics, underscores, parentheses, hyphens and periods",
+		end: notPeriod, endDescription: "any character except a period",
+	},
+	"Microsoft.ManagedIdentity/userAssignedIdentities": {
+		minLength: 3, maxLength: 128,
+		characters: alphanumericsUnderscoresHyphen, charactersDescription: "alphanumerics, hyphens and underscores",
+		start: alphanumericStart, startDescription: "a letter or number",
+	},
+	"Microsoft.ContainerService/managedClusters": {
+		minLength: 1, maxLength: 63,
+		characters: alphanumericsUnderscoresHyphen, charactersDescription: "alphanumerics, underscores and hyphens",
+		start: alphanumericStart, startDescription: "a letter or number",
+		end: alphanumericEnd, endDescription: "a letter or number",
+	},
+	"Microsoft.Network/virtualNetworks": {
+		minLength: 2, maxLength: 64,
+		characters: alphanumericsPeriodsEtc, charactersDescription: "alphanumerics, underscores, periods and hyphens",
+		start: alphanumericStart, startDescription: "a letter or number",
+		end: alphanumericUnderscores, endDescription: "a letter, number or underscore",
+	},
+	"Microsoft.Network/publicIPAddresses": {
+		minLength: 1, maxLength: 80,
+		characters: alphanumericsPeriodsEtc, charactersDescription: "alphanumerics, underscores, periods and hyphens",
+		start: alphanumericStart, startDescription: "a letter or number",
+		end: alphanumericUnderscores, endDescription: "a letter, number or underscore",
+	},
+	"Microsoft.DocumentDB/databaseAccounts": {
+		minLength: 3, maxLength: 44,
+		characters: lowercaseAlphanumericsHyphens, charactersDescription: "lowercase letters, numbers and hyphens",
+		start: regexp.MustCompile(`^[a-z0-9]`), startDescription: "a lowercase letter or number",
+	},
+	"Microsoft.EventGrid/namespaces": {
+		minLength: 3, maxLength: 50,
+		characters: alphanumericsAndHyphens, charactersDescription: "alphanumerics and hyphens",
+	},
+	"Microsoft.OperationalInsights/workspaces": {
+		minLength: 4, maxLength: 63,
+		characters: alphanumericsAndHyphens, charactersDescription: "alphanumerics and hyphens",
+		start: alphanumericStart, startDescription: "a letter or number",
+		end: alphanumericEnd, endDescription: "a letter or number",
+	},
+	"Microsoft.Kusto/clusters": {
+		minLength: 4, maxLength: 22,
+		characters: lowercaseAlphanumerics, charactersDescription: "lowercase letters and numbers",
+		start: lowercaseLetter, startDescription: "a lowercase letter",
+	},
+}
+
+// problems lists the ways in which a name breaks the rule.
+func (r namingRule) problems(name string) []string {
+	var problems []string
+	if length := len(name); length < r.minLength || length > r.maxLength {
+		problems = append(problems, fmt.Sprintf("must be between %d and %d characters long, not %d", r.minLength, r.maxLength, length))
+	}
+	if name == "" {
+		return problems
+	}
+	if !r.characters.MatchString(name) {
+		problems = append(problems, fmt.Sprintf("may only contain %s", r.charactersDescription))
+	}
+	if r.start != nil && !r.start.MatchString(name) {
+		problems = append(problems, fmt.Sprintf("must start with %s", r.startDescription))
+	}
+	if r.end != nil && !r.end.MatchString(name) {
+		problems = append(problems, fmt.Sprintf("must end with %s", r.endDescription))
+	}
+	if r.noConsecutiveHyphens && strings.Contains(name, "--") {
+		problems = append(problems, "may not contain consecutive hyphens")
+	}
+	return problems
+}
+
+// NamingViolation records a configuration value that is not a valid name for the Azure resource type it is used for.
+type NamingViolation struct {
+	Path         string
+	ResourceType string
+	Value        any
+	Problems     []string
+}
+
+func (v NamingViolation) String() string {
+	value := fmt.Sprintf("%v", v.Value)
+	if name, ok := v.Value.(string); ok {
+		value = strconv.Quote(name)
+	}
+	return fmt.Sprintf("%s: %s is not a valid %s name: %s", v.Path, value, v.ResourceType, strings.Join(v.Problems, ", "))
+}
+
+// NamingError records every configuration value that breaks the naming rules for its Azure resource type.
+type NamingError struct {
+	Violations []NamingViolation
+}
+
+func (e *NamingError) Error() string {
+	var messages []string
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.String())
+	}
+	return fmt.Sprintf("configuration values break Azure naming rules: %s", strings.Join(messages, "; "))
+}
+
+// resourceName records a key in the service schema which holds the name of an Azure resource.
+type resourceName struct {
+	path         []string
+	resourceType string
+}
+
+// findResourceNames finds all the keys in the schema document that hold names of Azure resources.
+func findResourceNames(document map[string]any) ([]resourceName, error) {
+	var names []resourceName
+	var errs []string
+	if err := walkSchema(document, func(path []string, node map[string]any) {
+		raw, set := node[resourceTypeKeyword]
+		if !set {
+			return
+		}
+		resourceType, _ := raw.(string)
+		if _, known := namingRules[resourceType]; !known {
+			errs = append(errs, fmt.Sprintf("%s: no naming rules are known for %s %v", strings.Join(path, "."), resourceTypeKeyword, raw))
+			return
+		}
+		names = append(names, resourceName{path: path, resourceType: resourceType})
+	}); err != nil {
+		return nil, fmt.Errorf("failed to walk schema: %w", err)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid schema annotations: %s", strings.Join(errs, "; "))
+	}
+	return names, nil
+}
+
+// checkResourceNames validates the configuration against the naming rules of the resource types its keys are marked with.
+func checkResourceNames(document map[string]any, config types.Configuration) error {
+	names, err := findResourceNames(document)
+	if err != nil {
+		return err
+	}
+	namingErr := &NamingError{}
+	for _, name := range names {
+		for _, path := range expandSchemaPath(config, name.path) {
+			value, err := config.GetByPath(path)
+			if err != nil {
+				return fmt.Errorf("failed to get %s: %w", path, err)
+			}
+			var problems []string
+			if str, ok := value.(string); ok {
+				problems = namingRules[name.resourceType].problems(str)
+			} else {
+				problems = []string{fmt.Sprintf("must be a string, not %T", value)}
+			}
+			if len(problems) > 0 {
+				namingErr.Violations = append(namingErr.Violations, NamingViolation{
+					Path:         path,
+					ResourceType: name.resourceType,
+					Value:        value,
+					Problems:     problems,
+				})
+			}
+		}
+	}
+	if len(namingErr.Violations) > 0 {
+		return namingErr
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Azure/ARO-Tools/pkg/config"
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+func TestValidateSchemaResourceNames(t *testing.T) {
+	schema := []byte(`{
+  "type": "object",
+  "properties": {
+    "keyVault": {"type": "string", "x-azure-resource-type": "Microsoft.KeyVault/vaults"},
+    "storage": {
+      "type": "object",
+      "additionalProperties": {"x-azure-resource-type": "Microsoft.Storage/storageAccounts"}
+    },
+    "resourceGroup": {"type": "string", "x-azure-resource-type": "Microsoft.Resources/resourceGroups"}
+  }
+}`)
+	for _, testCase := range []struct {
+		name       string
+		cfg        types.Configuration
+		violations []config.NamingViolation
+		// messages are expected in the error, for the violations
+		messages []string
+	}{
+		{
+			name: "valid names",
+			cfg: types.Configuration{
+				"keyVault":      "arohcp-svc-ln",
+				"storage":       map[string]any{"logs": "arohcplogsln"},
+				"resourceGroup": "hcp-underlay-(uksouth)_1",
+			},
+		},
+		{
+			name: "invalid names",
+			cfg: types.Configuration{
+				"keyVault":      "1-arohcp--svc-uksouth-int-",
+				"storage":       map[string]any{"logs": "ARO-HCP-logs", "metrics": "m", "events": 1},
+				"resourceGroup": "hcp.",
+			},
+			violations: []config.NamingViolation{
+				{Path: "keyVault", ResourceType: "Microsoft.KeyVault/vaults", Value: "1-arohcp--svc-uksouth-int-", Problems: []string{"must be between 3 and 24 characters long, not 26", "must start with a letter", "must end with a letter or digit", "may not contain consecutive hyphens"}},
+				{Path: "resourceGroup", ResourceType: "Microsoft.Resources/resourceGroups", Value: "hcp.", Problems: []string{"must end with any character except a period"}},
+				{Path: "storage.events", ResourceType: "Microsoft.Storage/storageAccounts", Value: float64(1), Problems: []string{"must be a string, not float64"}},
+				{Path: "storage.logs", ResourceType: "Microsoft.Storage/storageAccounts", Value: "ARO-HCP-logs", Problems: []string{"may only contain lowercase letters and numbers"}},
+				{Path: "storage.metrics", ResourceType: "Microsoft.Storage/storageAccounts", Value: "m", Problems: []string{"must be between 3 and 24 characters long, not 1"}},
+			},
+			messages: []string{
+				`resourceGroup: "hcp." is not a valid Microsoft.Resources/resourceGroups name: must end with any character except a period`,
+				"storage.events: 1 is not a valid Microsoft.Storage/storageAccounts name: must be a string, not float64",
+			},
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			resolver := uksouthResolver(t, testCase.cfg, schema)
+			cfg, err := resolver.GetRegionConfiguration("uksouth")
+			require.NoError(t, err)
+
+			err = resolver.ValidateSchema(cfg)
+			if testCase.violations == nil {
+				require.NoError(t, err)
+				return
+			}
+			var namingErr *config.NamingError
+			require.ErrorAs(t, err, &namingErr)
+			if diff := cmp.Diff(testCase.violations, namingErr.Violations); diff != "" {
+				t.Errorf("incorrect violations (-want, +got): %v", diff)
+			}
+			for _, message := range testCase.messages {
+				require.ErrorContains(t, err, message)
+			}
+		})
+	}
+}
@@ -0,0 +1,355 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template/parse"
+
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+// TemplateReference is a path to a value which a template references, like svc.subscription.key for
+// {{ .svc.subscription.key }}.
+type TemplateReference struct {
+	Path string
+	// Line and Column locate the reference in the template, starting from 1.
+	Line, Column int
+}
+
+// ExtractTemplateReferences parses a template without executing it and lists the paths to every value it references,
+// in the order they appear. References are found in fields of the root value (.a.b), fields of $ ($.a.b), lookups with
+// literal keys (index .a "b") and fields relative to the value of a `with` block. Fields relative to a value that
+// can't be known statically, like the elements of a `range`, are skipped.
+func ExtractTemplateReferences(content []byte) ([]TemplateReference, error) {
+	tree := parse.New("file")
+	tree.Mode = parse.SkipFuncCheck
+	trees := map[string]*parse.Tree{}
+	if _, err := tree.Parse(string(content), "", "", trees); err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	e := &referenceExtractor{content: string(content)}
+	for _, name := range sortedKeys(trees) {
+		var dot []string
+		if name == tree.ParseName {
+			// templates defined in the file may be executed with any value, so only the root dot is known
+			dot = []string{}
+		}
+		if trees[name].Root != nil {
+			e.node(trees[name].Root, dot)
+		}
+	}
+	sort.SliceStable(e.references, func(i, j int) bool {
+		if e.references[i].Line != e.references[j].Line {
+			return e.references[i].Line < e.references[j].Line
+		}
+		return e.references[i].Column < e.references[j].Column
+	})
+	return e.references, nil
+}
+
+type referenceExtractor struct {
+	content    string
+	references []TemplateReference
+}
+
+// node records references under a node; dot is the path to the value of dot, or nil when it can't be known.
+func (e *referenceExtractor) node(node parse.Node, dot []string) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			e.node(child, dot)
+		}
+	case *parse.ActionNode:
+		e.pipe(n.Pipe, dot)
+	case *parse.IfNode:
+		e.branch(&n.BranchNode, dot, dot)
+	case *parse.WithNode:
+		e.branch(&n.BranchNode, dot, e.pipePath(n.Pipe, dot))
+	case *parse.RangeNode:
+		e.branch(&n.BranchNode, dot, nil)
+	case *parse.TemplateNode:
+		e.pipe(n.Pipe, dot)
+	}
+}
+
+func (e *referenceExtractor) branch(n *parse.BranchNode, dot, inner []string) {
+	e.pipe(n.Pipe, dot)
+	e.node(n.List, inner)
+	e.node(n.ElseList, dot)
+}
+
+func (e *referenceExtractor) pipe(pipe *parse.PipeNode, dot []string) {
+	if pipe == nil {
+		return
+	}
+	for _, cmd := range pipe.Cmds {
+		if path, pos, ok := e.indexPath(cmd, dot); ok {
+			e.record(path, pos)
+			continue
+		}
+		for _, arg := range cmd.Args {
+			e.arg(arg, dot)
+		}
+	}
+}
+
+func (e *referenceExtractor) arg(arg parse.Node, dot []string) {
+	switch a := arg.(type) {
+	case *parse.PipeNode:
+		e.pipe(a, dot)
+	case *parse.ChainNode:
+		e.arg(a.Node, dot)
+	default:
+		if path, ok := e.path(arg, dot); ok {
+			e.record(path, arg.Position())
+		}
+	}
+}
+
+// path determines the path to the value a node refers to, if it can be known statically.
+func (e *referenceExtractor) path(node parse.Node, dot []string) ([]string, bool) {
+	switch n := node.(type) {
+	case *parse.FieldNode:
+		if dot == nil {
+			return nil, false
+		}
+		return append(append([]string{}, dot...), n.Ident...), true
+	case *parse.VariableNode:
+		if n.Ident[0] != "$" || len(n.Ident) < 2 {
+			return nil, false
+		}
+		return append([]string{}, n.Ident[1:]...), true
+	case *parse.DotNode:
+		if len(dot) == 0 {
+			return nil, false
+		}
+		return append([]string{}, dot...), true
+	}
+	return nil, false
+}
+
+// indexPath determines the path for lookups with literal keys, like index .a "b" "c".
+func (e *referenceExtractor) indexPath(cmd *parse.CommandNode, dot []string) ([]string, parse.Pos, bool) {
+	if len(cmd.Args) < 3 {
+		return nil, 0, false
+	}
+	if ident, ok := cmd.Args[0].(*parse.IdentifierNode); !ok || ident.Ident != "index" {
+		return nil, 0, false
+	}
+	path, ok := e.path(cmd.Args[1], dot)
+	if !ok {
+		return nil, 0, false
+	}
+	for _, key := range cmd.Args[2:] {
+		str, ok := key.(*parse.StringNode)
+		if !ok {
+			return nil, 0, false
+		}
+		path = append(path, str.Text)
+	}
+	return path, cmd.Args[1].Position(), true
+}
+
+// pipePath determines the path to the value of a pipeline, if it is just a reference.
+func (e *referenceExtractor) pipePath(pipe *parse.PipeNode, dot []string) []string {
+	if pipe == nil || len(pipe.Cmds) != 1 || len(pipe.Decl) > 0 {
+		return nil
+	}
+	if path, _, ok := e.indexPath(pipe.Cmds[0], dot); ok {
+		return path
+	}
+	if len(pipe.Cmds[0].Args) != 1 {
+		return nil
+	}
+	path, ok := e.path(pipe.Cmds[0].Args[0], dot)
+	if !ok {
+		return nil
+	}
+	return path
+}
+
+func (e *referenceExtractor) record(path []string, pos parse.Pos) {
+	if len(path) == 0 {
+		return
+	}
+	// text/template positions references at one of their fields, not at their start
+	start := int(pos)
+	for start > 0 && isReferenceCharacter(e.content[start-1]) {
+		start--
+	}
+	before := e.content[:start]
+	e.references = append(e.references, TemplateReference{
+		Path:   strings.Join(path, "."),
+		Line:   1 + strings.Count(before, "\n"),
+		Column: 1 + len(before) - (strings.LastIndex(before, "\n") + 1),
+	})
+}
+
+func isReferenceCharacter(c byte) bool {
+	return c == '.' || c == '$' || c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+// UnresolvableReference records a template reference that can never resolve.
+type UnresolvableReference struct {
+	TemplateReference
+	Reason string
+}
+
+func (r UnresolvableReference) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s", r.Line, r.Column, r.Path, r.Reason)
+}
+
+// CheckReferencesAgainstConfiguration finds the references that a resolved configuration does not hold values for.
+func CheckReferencesAgainstConfiguration(references []TemplateReference, cfg types.Configuration) []UnresolvableReference {
+	var unresolvable []UnresolvableReference
+	for _, reference := range references {
+		if _, err := cfg.GetByPath(reference.Path); err != nil {
+			unresolvable = append(unresolvable, UnresolvableReference{TemplateReference: reference, Reason: err.Error()})
+		}
+	}
+	return unresolvable
+}
+
+// CheckReferencesAgainstSchema finds the references that no configuration valid against the resolver's service schema
+// could hold values for: those that index into keys that a closed object does not declare, or into scalar values.
+// References into objects that allow arbitrary properties are assumed to resolve.
+func CheckReferencesAgainstSchema(resolver ConfigResolver, references []TemplateReference) ([]UnresolvableReference, error) {
+	document, err := resolver.SchemaDocument()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schema: %w", err)
+	}
+	if document == nil {
+		return nil, errors.New("the configuration is not registered as using a schema")
+	}
+	index, err := newSchemaIndex(document)
+	if err != nil {
+		return nil, err
+	}
+
+	var unresolvable []UnresolvableReference
+	for _, reference := range references {
+		if reason := index.unresolvable(strings.Split(reference.Path, ".")); reason != "" {
+			unresolvable = append(unresolvable, UnresolvableReference{TemplateReference: reference, Reason: reason})
+		}
+	}
+	return unresolvable, nil
+}
+
+// schemaIndex records the schemas describing each path in a schema document, with wildcard segments for keys of
+// objects that allow arbitrary properties.
+type schemaIndex struct {
+	document map[string]any
+	paths    map[string][]map[string]any
+}
+
+func newSchemaIndex(document map[string]any) (*schemaIndex, error) {
+	index := &schemaIndex{document: document, paths: map[string][]map[string]any{"": {document}}}
+	if err := walkSchema(document, func(path []string, node map[string]any) {
+		key := strings.Join(path, ".")
+		index.paths[key] = append(index.paths[key], node)
+	}); err != nil {
+		return nil, fmt.Errorf("failed to walk schema: %w", err)
+	}
+	return index, nil
+}
+
+// unresolvable explains why no valid configuration could hold a value at the path, or returns an empty string.
+func (i *schemaIndex) unresolvable(path []string) string {
+	parents := i.paths[""]
+	for depth := range path {
+		children := i.matching(path[:depth+1])
+		if len(children) > 0 {
+			parents = children
+			continue
+		}
+		for _, parent := range parents {
+			if !i.closed(parent) {
+				return ""
+			}
+		}
+		if depth == 0 {
+			return fmt.Sprintf("key %s is not defined in the schema", path[depth])
+		}
+		return fmt.Sprintf("key %s is not defined in the schema for %s", path[depth], strings.Join(path[:depth], "."))
+	}
+	return ""
+}
+
+// matching finds the schemas for a concrete path, matching wildcard segments against any key.
+func (i *schemaIndex) matching(path []string) []map[string]any {
+	var nodes []map[string]any
+	for key, candidates := range i.paths {
+		if key == "" {
+			continue
+		}
+		segments := strings.Split(key, ".")
+		if len(segments) != len(path) {
+			continue
+		}
+		matches := true
+		for j := range segments {
+			if segments[j] != wildcardSegment && segments[j] != path[j] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			nodes = append(nodes, candidates...)
+		}
+	}
+	return nodes
+}
+
+// closed determines if a schema rejects keys it does not declare, or does not describe an object at all.
+func (i *schemaIndex) closed(node map[string]any) bool {
+	for _, n := range i.resolved(node) {
+		if typ, ok := n["type"].(string); ok && typ != "object" {
+			return true
+		}
+		if additional, ok := n["additionalProperties"].(bool); ok && !additional {
+			if _, hasPatterns := n["patternProperties"]; !hasPatterns {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolved follows local references from a schema, returning it along with every schema it refers to.
+func (i *schemaIndex) resolved(node map[string]any) []map[string]any {
+	nodes := []map[string]any{node}
+	seen := map[string]bool{}
+	for current := node; ; {
+		ref, ok := current["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#") || seen[ref] {
+			return nodes
+		}
+		seen[ref] = true
+		target, err := resolveLocalReference(i.document, ref)
+		if err != nil {
+			return nodes
+		}
+		nodes = append(nodes, target)
+		current = target
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Azure/ARO-Tools/pkg/config"
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+func TestTemplateReferences(t *testing.T) {
+	content := []byte(`name: {{ .svc.name }}
+subscription: {{ index .svc "subscription" "key" }}
+{{- with .svc.image }}
+image: {{ .registry }}/{{ .repository }}:{{ $.svc.tag }}
+{{- end }}
+{{- range .svc.regions }}
+region: {{ .name }}
+{{- end }}
+{{- if .global.enabled }}
+typo: {{ .svc.nmae | quote }}
+{{- end }}
+dns: {{ .dns.zones.public }}
+cluster: {{ .svc.cluster.name }}
+`)
+	references, err := config.ExtractTemplateReferences(content)
+	require.NoError(t, err)
+	if diff := cmp.Diff([]config.TemplateReference{
+		{Path: "svc.name", Line: 1, Column: 10},
+		{Path: "svc.subscription.key", Line: 2, Column: 24},
+		{Path: "svc.image", Line: 3, Column: 10},
+		{Path: "svc.image.registry", Line: 4, Column: 11},
+		{Path: "svc.image.repository", Line: 4, Column: 27},
+		{Path: "svc.tag", Line: 4, Column: 45},
+		{Path: "svc.regions", Line: 6, Column: 11},
+		{Path: "global.enabled", Line: 9, Column: 8},
+		{Path: "svc.nmae", Line: 10, Column: 10},
+		{Path: "dns.zones.public", Line: 12, Column: 9},
+		{Path: "svc.cluster.name", Line: 13, Column: 13},
+	}, references); diff != "" {
+		t.Errorf("incorrect references (-want, +got): %v", diff)
+	}
+
+	cfg := types.Configuration{
+		"global": map[string]any{"enabled": true},
+		"svc": map[string]any{
+			"name":         "svc",
+			"subscription": map[string]any{"key": "sub"},
+			"image":        map[string]any{"registry": "arohcp.azurecr.io", "repository": "svc"},
+			"tag":          "latest",
+			"regions":      []any{},
+			"cluster":      "aks",
+		},
+		"dns": map[string]any{"zones": map[string]any{"public": "example.com"}},
+	}
+	var unresolvable []string
+	for _, reference := range config.CheckReferencesAgainstConfiguration(references, cfg) {
+		unresolvable = append(unresolvable, reference.String())
+	}
+	require.Equal(t, []string{
+		"10:10: svc.nmae: configuration[svc]: key nmae not found",
+		"13:13: svc.cluster.name: configuration[svc][cluster]: expected nested map, found string; cannot index with name",
+	}, unresolvable)
+
+	resolver := uksouthResolver(t, cfg, []byte(`{
+  "type": "object",
+  "additionalProperties": false,
+  "definitions": {
+    "svc": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "name": {"type": "string"},
+        "tag": {"type": "string"},
+        "cluster": {"type": "string"},
+        "regions": {"type": "array"},
+        "subscription": {"type": "object"},
+        "image": {"type": "object", "properties": {"registry": {"type": "string"}}}
+      }
+    }
+  },
+  "properties": {
+    "global": {"type": "object", "additionalProperties": false, "properties": {"enabled": {"type": "boolean"}}},
+    "svc": {"$ref": "#/definitions/svc"},
+    "dns": {"type": "object", "additionalProperties": {"type": "object"}}
+  }
+}`))
+	fromSchema, err := config.CheckReferencesAgainstSchema(wrappedResolver{ConfigResolver: resolver}, references)
+	require.NoError(t, err)
+	unresolvable = nil
+	for _, reference := range fromSchema {
+		unresolvable = append(unresolvable, reference.String())
+	}
+	require.Equal(t, []string{
+		"10:10: svc.nmae: key nmae is not defined in the schema for svc",
+		"13:13: svc.cluster.name: key name is not defined in the schema for svc.cluster",
+	}, unresolvable)
+}
@@ -0,0 +1,542 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"go.yaml.in/yaml/v3"
+
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+	"github.com/Azure/ARO-Tools/pkg/yamlwrap"
+)
+
+// RenameConfigurationKey moves the value at one path to another in every set of overrides in a raw configuration
+// file: the defaults and the overrides for every cloud, environment, region and stamp. Like MigrateConfiguration, the
+// file is not pre-processed, so comments and template expressions are preserved verbatim. It is an error for no set of
+// overrides to hold the path, or for one to already hold the new path.
+func RenameConfigurationKey(raw []byte, from, to string) ([]byte, error) {
+	fromParts, toParts, err := renamePaths(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped, err := yamlwrap.WrapYAML(raw, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap configuration: %w", err)
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(wrapped, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration: %w", err)
+	}
+	if document.Kind != yaml.DocumentNode || len(document.Content) != 1 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("configuration must be a mapping")
+	}
+	root := document.Content[0]
+
+	metaSchema := defaultMetaSchemaRef
+	if value := mappingValue(root, "$metaSchema"); value != nil {
+		metaSchema = value.Value
+	}
+
+	var moved int
+	for _, overrides := range overrideNodes(root, metaSchema == metaSchemaV1Ref) {
+		key, value, err := detachNode(overrides.node, fromParts, true)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", overrides.name, err)
+		}
+		if key == nil {
+			continue
+		}
+		key.Value = toParts[len(toParts)-1]
+		if err := attachNode(overrides.node, toParts, key, value, func() *yaml.Node {
+			return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}); err != nil {
+			return nil, fmt.Errorf("%s: %w", overrides.name, err)
+		}
+		moved++
+	}
+	if moved == 0 {
+		return nil, fmt.Errorf("no overrides set %s", from)
+	}
+
+	out := bytes.Buffer{}
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	unwrapped, err := yamlwrap.UnwrapYAML(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap configuration: %w", err)
+	}
+	return unwrapped, nil
+}
+
+type namedOverrides struct {
+	name string
+	node *yaml.Node
+}
+
+// overrideNodes lists every set of overrides in a configuration document, naming each for errors.
+func overrideNodes(root *yaml.Node, v1 bool) []namedOverrides {
+	var out []namedOverrides
+	add := func(name string, node *yaml.Node) {
+		if node != nil && node.Kind == yaml.MappingNode {
+			out = append(out, namedOverrides{name: name, node: node})
+		}
+	}
+	add("defaults", mappingValue(root, "defaults"))
+	clouds := mappingValue(root, "clouds")
+	for _, cloud := range mappingEntries(clouds) {
+		add(cloud.key, mappingValue(cloud.value, "defaults"))
+		for _, environment := range mappingEntries(mappingValue(cloud.value, "environments")) {
+			name := cloud.key + "/" + environment.key
+			add(name, mappingValue(environment.value, "defaults"))
+			for _, region := range mappingEntries(mappingValue(environment.value, "regions")) {
+				name := name + "/" + region.key
+				if v1 {
+					add(name, region.value)
+					continue
+				}
+				add(name, mappingValue(region.value, "defaults"))
+				for _, stamp := range mappingEntries(mappingValue(region.value, "stamps")) {
+					add(name+"/"+stamp.key, stamp.value)
+				}
+			}
+		}
+	}
+	return out
+}
+
+type mappingEntry struct {
+	key   string
+	value *yaml.Node
+}
+
+// mappingEntries returns all the keys and values in a mapping node.
+func mappingEntries(node *yaml.Node) []mappingEntry {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	var entries []mappingEntry
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		entries = append(entries, mappingEntry{key: node.Content[i].Value, value: node.Content[i+1]})
+	}
+	return entries
+}
+
+// renamePaths splits and validates the paths for a rename.
+func renamePaths(from, to string) ([]string, []string, error) {
+	for _, path := range []string{from, to} {
+		if !validRenamePath(path) {
+			return nil, nil, fmt.Errorf("invalid path %q: must be in dot notation format (e.g., 'key' or 'parent.child')", path)
+		}
+	}
+	if from == to {
+		return nil, nil, fmt.Errorf("cannot rename %s to itself", from)
+	}
+	if strings.HasPrefix(to, from+".") || strings.HasPrefix(from, to+".") {
+		return nil, nil, fmt.Errorf("cannot rename %s to %s: one path contains the other", from, to)
+	}
+	return strings.Split(from, "."), strings.Split(to, "."), nil
+}
+
+func validRenamePath(path string) bool {
+	if path == "" {
+		return false
+	}
+	for _, part := range strings.Split(path, ".") {
+		if part == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// detachNode removes the key at the path from a tree of mappings, returning the key and value nodes, or nil if the
+// path is not present. When prune is set, mappings left empty by the removal are removed, too.
+func detachNode(node *yaml.Node, parts []string, prune bool) (*yaml.Node, *yaml.Node, error) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != parts[0] {
+			continue
+		}
+		key, value := node.Content[i], node.Content[i+1]
+		if len(parts) == 1 {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return key, value, nil
+		}
+		if value.Kind != yaml.MappingNode {
+			return nil, nil, fmt.Errorf("%s: expected a mapping", key.Value)
+		}
+		detachedKey, detachedValue, err := detachNode(value, parts[1:], prune)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s.%w", key.Value, err)
+		}
+		if prune && detachedKey != nil && len(value.Content) == 0 {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+		}
+		return detachedKey, detachedValue, nil
+	}
+	return nil, nil, nil
+}
+
+// attachNode adds the key and value at the path in a tree of mappings, creating missing mappings along the way.
+func attachNode(node *yaml.Node, parts []string, key, value *yaml.Node, newMapping func() *yaml.Node) error {
+	existing := mappingValue(node, parts[0])
+	if len(parts) == 1 {
+		if existing != nil {
+			return fmt.Errorf("%s is already set", parts[0])
+		}
+		node.Content = append(node.Content, key, value)
+		return nil
+	}
+	if existing == nil {
+		existing = newMapping()
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: parts[0]}, existing)
+	}
+	if existing.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: expected a mapping", parts[0])
+	}
+	if err := attachNode(existing, parts[1:], key, value, newMapping); err != nil {
+		return fmt.Errorf("%s.%w", parts[0], err)
+	}
+	return nil
+}
+
+// RenameSchemaProperty moves the schema for the property at one path to another in a service schema, keeping the
+// property required if it was. Properties are followed through nested `properties` keywords; a path through a
+// `$ref` can't be renamed, since the referenced schema may be shared. The order of keys and the indentation of the
+// document are preserved.
+func RenameSchemaProperty(raw []byte, from, to string) ([]byte, error) {
+	fromParts, toParts, err := renamePaths(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(raw, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	if document.Kind != yaml.DocumentNode || len(document.Content) != 1 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("schema must be an object")
+	}
+	root := document.Content[0]
+
+	fromParent, err := schemaObject(root, fromParts[:len(fromParts)-1], false)
+	if err != nil {
+		return nil, err
+	}
+	if fromParent == nil {
+		return nil, fmt.Errorf("schema has no property %s", from)
+	}
+	properties := mappingValue(fromParent, "properties")
+	if properties == nil {
+		return nil, fmt.Errorf("schema has no property %s", from)
+	}
+	key, value, err := detachNode(properties, fromParts[len(fromParts)-1:], false)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, fmt.Errorf("schema has no property %s", from)
+	}
+	required := removeRequired(fromParent, fromParts[len(fromParts)-1])
+
+	toParent, err := schemaObject(root, toParts[:len(toParts)-1], true)
+	if err != nil {
+		return nil, err
+	}
+	key.Value = toParts[len(toParts)-1]
+	if err := attachNode(schemaProperties(toParent), toParts[len(toParts)-1:], key, value, nil); err != nil {
+		return nil, fmt.Errorf("schema property %w", err)
+	}
+	if required {
+		addRequired(toParent, key.Value)
+	}
+
+	out := bytes.Buffer{}
+	if err := encodeJSONNode(&out, root, jsonIndent(raw), 0); err != nil {
+		return nil, fmt.Errorf("failed to encode schema: %w", err)
+	}
+	out.WriteString("\n")
+	return out.Bytes(), nil
+}
+
+// schemaObject finds the schema for the object at the path, optionally creating it.
+func schemaObject(root *yaml.Node, parts []string, create bool) (*yaml.Node, error) {
+	current := root
+	for i, part := range parts {
+		properties := mappingValue(current, "properties")
+		if properties == nil && mappingValue(current, "$ref") != nil {
+			return nil, fmt.Errorf("cannot rename through $ref in the schema for %s", strings.Join(parts[:i], "."))
+		}
+		next := mappingValue(properties, part)
+		if next == nil {
+			if !create {
+				return nil, nil
+			}
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+				{Kind: yaml.ScalarNode, Tag: "!!str", Value: "type"},
+				{Kind: yaml.ScalarNode, Tag: "!!str", Value: "object"},
+			}}
+			properties = schemaProperties(current)
+			properties.Content = append(properties.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part}, next)
+		}
+		if next.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("the schema for %s must be an object", strings.Join(parts[:i+1], "."))
+		}
+		current = next
+	}
+	if properties := mappingValue(current, "properties"); properties == nil && mappingValue(current, "$ref") != nil {
+		return nil, fmt.Errorf("cannot rename through $ref in the schema for %s", strings.Join(parts, "."))
+	}
+	return current, nil
+}
+
+// schemaProperties returns the properties of an object schema, adding the keyword if it is missing.
+func schemaProperties(schema *yaml.Node) *yaml.Node {
+	if properties := mappingValue(schema, "properties"); properties != nil {
+		return properties
+	}
+	properties := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	schema.Content = append(schema.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "properties"}, properties)
+	return properties
+}
+
+// removeRequired removes a property from the required list of an object schema, returning whether it was present.
+func removeRequired(schema *yaml.Node, property string) bool {
+	required := mappingValue(schema, "required")
+	if required == nil || required.Kind != yaml.SequenceNode {
+		return false
+	}
+	for i, item := range required.Content {
+		if item.Value == property {
+			required.Content = append(required.Content[:i], required.Content[i+1:]...)
+			if len(required.Content) == 0 {
+				_, _, _ = detachNode(schema, []string{"required"}, false)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// addRequired adds a property to the required list of an object schema.
+func addRequired(schema *yaml.Node, property string) {
+	required := mappingValue(schema, "required")
+	if required == nil {
+		required = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		schema.Content = append(schema.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "required"}, required)
+	}
+	required.Content = append(required.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: property})
+}
+
+// jsonIndent determines the indentation of a JSON document from its first indented line, defaulting to two spaces.
+func jsonIndent(raw []byte) string {
+	for _, line := range strings.Split(string(raw), "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && len(trimmed) < len(line) {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+	return "  "
+}
+
+// encodeJSONNode writes a node parsed from a JSON document back out as JSON, keeping the order of keys.
+func encodeJSONNode(out *bytes.Buffer, node *yaml.Node, indent string, depth int) error {
+	newline := func(depth int) {
+		out.WriteString("\n")
+		out.WriteString(strings.Repeat(indent, depth))
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			out.WriteString("{}")
+			return nil
+		}
+		out.WriteString("{")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				out.WriteString(",")
+			}
+			newline(depth + 1)
+			if err := encodeJSONString(out, node.Content[i].Value); err != nil {
+				return err
+			}
+			out.WriteString(": ")
+			if err := encodeJSONNode(out, node.Content[i+1], indent, depth+1); err != nil {
+				return err
+			}
+		}
+		newline(depth)
+		out.WriteString("}")
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			out.WriteString("[]")
+			return nil
+		}
+		out.WriteString("[")
+		for i, item := range node.Content {
+			if i > 0 {
+				out.WriteString(",")
+			}
+			newline(depth + 1)
+			if err := encodeJSONNode(out, item, indent, depth+1); err != nil {
+				return err
+			}
+		}
+		newline(depth)
+		out.WriteString("]")
+	case yaml.ScalarNode:
+		switch node.Tag {
+		case "!!str":
+			return encodeJSONString(out, node.Value)
+		case "!!int", "!!float", "!!bool", "!!null":
+			out.WriteString(node.Value)
+		default:
+			return fmt.Errorf("unexpected scalar %q with tag %s", node.Value, node.Tag)
+		}
+	default:
+		return fmt.Errorf("unexpected node kind %v", node.Kind)
+	}
+	return nil
+}
+
+func encodeJSONString(out *bytes.Buffer, value string) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	// the encoder terminates every value with a newline
+	out.Truncate(out.Len() - 1)
+	return nil
+}
+
+// VerifyRename checks that renaming a key changed nothing else: every context the original configuration resolves
+// must resolve identically after the rename, once the value at 'from' in the original is moved to 'to'. The renamed
+// configurations are also validated against the renamed provider's schema.
+func VerifyRename(before []ResolvedContext, after ConfigProvider, from, to string) error {
+	renamed, err := ResolveAllContexts(after)
+	if err != nil {
+		return fmt.Errorf("failed to resolve renamed configuration: %w", err)
+	}
+	if len(renamed) != len(before) {
+		return fmt.Errorf("renamed configuration has %d contexts, expected %d", len(renamed), len(before))
+	}
+
+	var errs []error
+	for i, original := range before {
+		if original.String() != renamed[i].String() {
+			return fmt.Errorf("renamed configuration has context %s, expected %s", renamed[i], original)
+		}
+		expected := moveValue(original.Configuration, strings.Split(from, "."), strings.Split(to, "."))
+		if !reflect.DeepEqual(expected, renamed[i].Configuration) {
+			sensitivity, err := SensitivityFor(renamed[i].Resolver, types.DefaultSensitiveKeyPatterns)
+			if err != nil {
+				return err
+			}
+			// the diff ends up in logs, so sensitive values must not show up in it
+			diff, err := configurationDiff(sensitivity.Redact(expected), sensitivity.Redact(renamed[i].Configuration))
+			if err != nil {
+				return fmt.Errorf("%s: %w", original, err)
+			}
+			if diff == "" {
+				diff = "only sensitive values differ"
+			}
+			errs = append(errs, fmt.Errorf("%s: configuration differs after renaming %s to %s:\n%s", original, from, to, diff))
+			continue
+		}
+		if err := renamed[i].Resolver.ValidateSchema(renamed[i].Configuration); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", original, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// configurationDiff renders two configurations as indented JSON, with sorted keys, and returns a unified diff of them.
+func configurationDiff(want, got any) (string, error) {
+	var lines [2][]string
+	for i, cfg := range []any{want, got} {
+		encoded, err := json.MarshalIndent(cfg, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to encode configuration: %w", err)
+		}
+		lines[i] = difflib.SplitLines(string(encoded) + "\n")
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        lines[0],
+		B:        lines[1],
+		FromFile: "want",
+		ToFile:   "got",
+		Context:  3,
+	})
+}
+
+// moveValue returns a copy of the configuration with the value at one path moved to another.
+func moveValue(cfg types.Configuration, from, to []string) types.Configuration {
+	out := cfg.DeepCopy()
+	parent := map[string]any(out)
+	for _, part := range from[:len(from)-1] {
+		next, ok := parent[part].(map[string]any)
+		if !ok {
+			return out
+		}
+		parent = next
+	}
+	value, ok := parent[from[len(from)-1]]
+	if !ok {
+		return out
+	}
+	delete(parent, from[len(from)-1])
+	pruneEmpty(map[string]any(out), from[:len(from)-1])
+
+	parent = out
+	for _, part := range to[:len(to)-1] {
+		next, ok := parent[part].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			parent[part] = next
+		}
+		parent = next
+	}
+	parent[to[len(to)-1]] = value
+	return out
+}
+
+// pruneEmpty removes the maps along the path that are left empty.
+func pruneEmpty(cfg map[string]any, path []string) {
+	if len(path) == 0 {
+		return
+	}
+	child, ok := cfg[path[0]].(map[string]any)
+	if !ok {
+		return
+	}
+	pruneEmpty(child, path[1:])
+	if len(child) == 0 {
+		delete(cfg, path[0])
+	}
+}
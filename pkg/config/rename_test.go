@@ -0,0 +1,73 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Azure/ARO-Tools/internal/testutil"
+	"github.com/Azure/ARO-Tools/pkg/config"
+)
+
+func TestRenameConfigurationKey(t *testing.T) {
+	raw, err := os.ReadFile("testdata/rename/config.yaml")
+	require.NoError(t, err)
+	rawSchema, err := os.ReadFile("testdata/rename/config.schema.json")
+	require.NoError(t, err)
+
+	renamed, err := config.RenameConfigurationKey(raw, "svc.image.digest", "images.svc.digest")
+	require.NoError(t, err)
+	testutil.CompareWithFixture(t, renamed, testutil.WithExtension(".yaml"))
+
+	renamedSchema, err := config.RenameSchemaProperty(rawSchema, "svc.image.digest", "images.svc.digest")
+	require.NoError(t, err)
+	testutil.CompareWithFixture(t, renamedSchema, testutil.WithExtension(".json"))
+
+	before, err := config.NewConfigProvider("testdata/rename/config.yaml")
+	require.NoError(t, err)
+	contexts, err := config.ResolveAllContexts(before)
+	require.NoError(t, err)
+	after, err := config.NewConfigProviderFromData(renamed, "testdata/rename", config.WithSchemaContent(renamedSchema))
+	require.NoError(t, err)
+	require.NoError(t, config.VerifyRename(contexts, after, "svc.image.digest", "images.svc.digest"))
+
+	// a provider that hasn't been renamed doesn't match
+	err = config.VerifyRename(contexts, before, "svc.image.digest", "images.svc.digest")
+	require.ErrorContains(t, err, "configuration differs after renaming")
+	require.ErrorContains(t, err, "--- want\n+++ got\n")
+	require.ErrorContains(t, err, "+      \"digest\": \"sha256:int\",")
+
+	for _, testCase := range []struct {
+		name, from, to string
+		err            string
+	}{
+		{name: "missing key", from: "svc.missing", to: "svc.other", err: "no overrides set svc.missing"},
+		{name: "existing target", from: "svc.replicas", to: "regionRG", err: "defaults: regionRG is already set"},
+		{name: "through a scalar", from: "svc.replicas.count", to: "replicas", err: "defaults: svc.replicas: expected a mapping"},
+		{name: "into itself", from: "svc", to: "svc.inner", err: "one path contains the other"},
+		{name: "invalid path", from: "svc..image", to: "image", err: `invalid path "svc..image"`},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := config.RenameConfigurationKey(raw, testCase.from, testCase.to)
+			require.ErrorContains(t, err, testCase.err)
+		})
+	}
+
+	_, err = config.RenameSchemaProperty([]byte(`{"properties": {"svc": {"$ref": "#/definitions/svc"}}}`), "svc.image", "image")
+	require.ErrorContains(t, err, "cannot rename through $ref in the schema for svc")
+}
@@ -0,0 +1,175 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package render preprocesses every templated file deployed from an entrypoint of a topology, for every context, so
+// that the result of a change to the configuration or to a template can be reviewed before it is deployed.
+package render
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Azure/ARO-Tools/pkg/config"
+	types2 "github.com/Azure/ARO-Tools/pkg/config/types"
+	"github.com/Azure/ARO-Tools/pkg/topology"
+	"github.com/Azure/ARO-Tools/pkg/types"
+)
+
+// Render preprocesses the pipelines of the services deployed from the entrypoint, along with the .bicepparam, Helm
+// values and namespace files they deploy, with the configuration of every context. Rendered files are keyed by the
+// path to write them at: cloud/environment/region/ followed by the path to the source relative to the directory holding
+// the topology. Values assigned to keys the schema of the configuration marks as sensitive, or whose names match one of
+// the sensitive key patterns, are redacted from every rendered file unless showSensitive is set.
+func Render(topologyPath, entrypoint string, contexts []config.ResolvedContext, sensitiveKeyPatterns []string, showSensitive bool) (map[string][]byte, error) {
+	topo, err := topology.Load(topologyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load topology %s: %w", topologyPath, err)
+	}
+	if err := topo.Validate(); err != nil {
+		return nil, fmt.Errorf("failed to validate topology %s: %w", topologyPath, err)
+	}
+	var found bool
+	for _, candidate := range topo.Entrypoints {
+		if candidate.Identifier == entrypoint {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("entrypoint %s not found in topology %s", entrypoint, topologyPath)
+	}
+	root, err := topo.Lookup(entrypoint)
+	if err != nil {
+		return nil, err
+	}
+
+	topologyDir := filepath.Dir(topologyPath)
+	var pipelines []string
+	var walk func(service topology.Service) error
+	walk = func(service topology.Service) error {
+		pipelinePath := service.PipelinePath
+		if pipelinePath == "" {
+			pipelinePath = service.Metadata["pipeline"]
+		}
+		if pipelinePath == "" {
+			return fmt.Errorf("service %s has no pipeline", service.ServiceGroup)
+		}
+		pipelines = append(pipelines, filepath.Join(topologyDir, pipelinePath))
+		for _, child := range service.Children {
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(*root); err != nil {
+		return nil, err
+	}
+
+	out := map[string][]byte{}
+	for _, ctx := range contexts {
+		var sensitivity *types2.Sensitivity
+		if !showSensitive {
+			sensitivity, err = config.SensitivityFor(ctx.Resolver, sensitiveKeyPatterns)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", ctx.String(), err)
+			}
+		}
+		for _, pipelinePath := range pipelines {
+			if err := renderPipeline(topologyDir, pipelinePath, ctx, sensitivity, out); err != nil {
+				return nil, fmt.Errorf("%s: %w", ctx.String(), err)
+			}
+		}
+	}
+	return out, nil
+}
+
+// renderPipeline preprocesses a pipeline and the files it deploys for one context, redacting sensitive values.
+func renderPipeline(topologyDir, pipelinePath string, ctx config.ResolvedContext, sensitivity *types2.Sensitivity, into map[string][]byte) error {
+	pipeline, err := types.NewPipelineFromFile(pipelinePath, ctx.Configuration)
+	if err != nil {
+		return fmt.Errorf("failed to load pipeline %s: %w", pipelinePath, err)
+	}
+
+	files := []string{pipelinePath}
+	for _, file := range pipeline.TemplatedFiles() {
+		files = append(files, filepath.Join(filepath.Dir(pipelinePath), file))
+	}
+	for _, file := range files {
+		relative, err := filepath.Rel(topologyDir, file)
+		if err != nil {
+			return fmt.Errorf("failed to determine path to %s relative to the topology: %w", file, err)
+		}
+		if relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("%s is outside of the directory holding the topology", file)
+		}
+		key := filepath.ToSlash(filepath.Join(ctx.Cloud, ctx.Environment, ctx.Region, relative))
+		if _, rendered := into[key]; rendered {
+			continue
+		}
+		rendered, err := config.PreprocessFile(file, ctx.Configuration)
+		if err != nil {
+			return err
+		}
+		into[key] = []byte(sensitivity.RedactText(string(rendered)))
+	}
+	return nil
+}
+
+// MarkerFile is written to the output directory to record that its contents were rendered, and may be replaced.
+const MarkerFile = ".rendered"
+
+// Write replaces the contents of the output directory with the rendered files, so that files which are no longer
+// rendered are removed. To protect other data, the output directory must not exist, be empty, or hold the MarkerFile
+// from an earlier Write.
+func Write(files map[string][]byte, outputDir string) error {
+	entries, err := os.ReadDir(outputDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read output directory: %w", err)
+	}
+	if len(entries) > 0 {
+		if _, err := os.Stat(filepath.Join(outputDir, MarkerFile)); err != nil {
+			return fmt.Errorf("refusing to replace the contents of %s: the directory is not empty and has no %s file, so it was not rendered into", outputDir, MarkerFile)
+		}
+	}
+	if err := os.RemoveAll(outputDir); err != nil {
+		return fmt.Errorf("failed to remove output directory: %w", err)
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, MarkerFile), []byte("The contents of this directory are replaced every time files are rendered into it.\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write marker file: %w", err)
+	}
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		target := filepath.Join(outputDir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+		if err := os.WriteFile(target, files[path], 0644); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", target, err)
+		}
+	}
+	return nil
+}
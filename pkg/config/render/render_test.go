@@ -0,0 +1,117 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Azure/ARO-Tools/internal/testutil"
+	"github.com/Azure/ARO-Tools/pkg/config"
+	"github.com/Azure/ARO-Tools/pkg/config/render"
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+func TestRender(t *testing.T) {
+	provider, err := config.NewConfigProvider("testdata/config.yaml")
+	require.NoError(t, err)
+	contexts, err := config.ResolveAllContexts(provider)
+	require.NoError(t, err)
+
+	files, err := render.Render("testdata/topology.yaml", "Microsoft.Azure.ARO.Test.Region", contexts, types.DefaultSensitiveKeyPatterns, false)
+	require.NoError(t, err)
+	rendered := map[string]string{}
+	for path, content := range files {
+		rendered[path] = string(content)
+	}
+	testutil.CompareWithFixture(t, rendered)
+
+	output := filepath.Join(t.TempDir(), "rendered")
+	require.NoError(t, render.Write(files, output))
+	written, err := os.ReadFile(filepath.Join(output, "public", "int", "uksouth", "cluster", "values.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, rendered["public/int/uksouth/cluster/values.yaml"], string(written))
+	require.FileExists(t, filepath.Join(output, render.MarkerFile))
+
+	// files from an earlier render are replaced
+	stale := filepath.Join(output, "public", "int", "westus3", "region", "pipeline.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(stale), 0755))
+	require.NoError(t, os.WriteFile(stale, []byte("stale"), 0644))
+	require.NoError(t, render.Write(files, output))
+	require.NoFileExists(t, stale)
+}
+
+func TestRenderRedactsSensitiveValues(t *testing.T) {
+	provider, err := config.NewConfigProvider("../testdata/sensitivity/config.yaml")
+	require.NoError(t, err)
+	contexts, err := config.ResolveAllContexts(provider)
+	require.NoError(t, err)
+
+	files, err := render.Render("testdata/sensitive/topology.yaml", "Microsoft.Azure.ARO.Test.Sensitive", contexts, types.DefaultSensitiveKeyPatterns, false)
+	require.NoError(t, err)
+	output := filepath.Join(t.TempDir(), "rendered")
+	require.NoError(t, render.Write(files, output))
+	var written []string
+	require.NoError(t, filepath.WalkDir(output, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, secret := range []string{"default-password", "int-certificate", "uksouth-key"} {
+			require.NotContains(t, string(content), secret, "%s holds a sensitive value", path)
+		}
+		written = append(written, string(content))
+		return nil
+	}))
+	require.Contains(t, written, "db:\n  host: db.public.example.com\n  adminPassword: <redacted>\nfrontend:\n  id: frontend\n  key: <redacted>\ncertificate: <redacted>\n")
+
+	files, err = render.Render("testdata/sensitive/topology.yaml", "Microsoft.Azure.ARO.Test.Sensitive", contexts, types.DefaultSensitiveKeyPatterns, true)
+	require.NoError(t, err)
+	require.Equal(t, "db:\n  host: db.public.example.com\n  adminPassword: default-password\nfrontend:\n  id: frontend\n  key: uksouth-key\ncertificate: int-certificate\n", string(files["public/int/uksouth/service/values.yaml"]))
+}
+
+func TestWriteRefusesUnrenderedDirectory(t *testing.T) {
+	output := t.TempDir()
+	require.NoError(t, render.Write(nil, output), "an empty directory may be rendered into")
+
+	output = t.TempDir()
+	precious := filepath.Join(output, "precious.txt")
+	require.NoError(t, os.WriteFile(precious, []byte("precious"), 0644))
+	err := render.Write(map[string][]byte{"public/int/uksouth/pipeline.yaml": []byte("rendered")}, output)
+	require.ErrorContains(t, err, "refusing to replace the contents of "+output)
+	require.FileExists(t, precious)
+	require.NoFileExists(t, filepath.Join(output, "public", "int", "uksouth", "pipeline.yaml"))
+}
+
+func TestRenderErrors(t *testing.T) {
+	provider, err := config.NewConfigProvider("testdata/config.yaml")
+	require.NoError(t, err)
+	contexts, err := config.ResolveAllContexts(provider)
+	require.NoError(t, err)
+
+	_, err = render.Render("testdata/topology.yaml", "Microsoft.Azure.ARO.Test.Cluster", contexts, types.DefaultSensitiveKeyPatterns, false)
+	require.EqualError(t, err, "entrypoint Microsoft.Azure.ARO.Test.Cluster not found in topology testdata/topology.yaml")
+
+	_, err = render.Render("testdata/topology.yaml", "Microsoft.Azure.ARO.Test.Other", contexts, types.DefaultSensitiveKeyPatterns, false)
+	require.ErrorContains(t, err, "public/int/eastus: failed to load pipeline testdata/other/pipeline.yaml")
+	require.ErrorContains(t, err, "missing key missing")
+}
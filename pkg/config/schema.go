@@ -0,0 +1,186 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+// wildcardSegment is used in schema paths to denote any key of an object that allows arbitrary properties.
+const wildcardSegment = "*"
+
+// loadSchemaDocument reads the JSONSchema document at path without compiling it, so that extension keywords
+// which the compiler ignores remain available for inspection.
+func loadSchemaDocument(path string) (map[string]any, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema %s: %w", path, err)
+	}
+	return unmarshalSchemaDocument(path, raw)
+}
+
+func unmarshalSchemaDocument(path string, raw []byte) (map[string]any, error) {
+	document := map[string]any{}
+	if err := json.Unmarshal(raw, &document); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schema %s: %w", path, err)
+	}
+	return document, nil
+}
+
+// walkSchema traverses the object properties declared in a JSONSchema document, following local references, and
+// calls visit with the path to each property and the schema that describes it. Keys of objects that allow arbitrary
+// properties are represented in the path with a wildcard segment.
+func walkSchema(document map[string]any, visit func(path []string, node map[string]any)) error {
+	return (&schemaWalker{document: document, visit: visit, visiting: map[string]bool{}}).walk(nil, document)
+}
+
+type schemaWalker struct {
+	document map[string]any
+	visit    func(path []string, node map[string]any)
+	// visiting records the references on the current branch, to guard against recursive schemas
+	visiting map[string]bool
+}
+
+func (w *schemaWalker) walk(path []string, node map[string]any) error {
+	// references to other documents are not followed, so annotations must be made in the service schema itself
+	if ref, ok := node["$ref"].(string); ok && strings.HasPrefix(ref, "#") {
+		if w.visiting[ref] {
+			return nil
+		}
+		target, err := resolveLocalReference(w.document, ref)
+		if err != nil {
+			return fmt.Errorf("%s: %w", strings.Join(path, "."), err)
+		}
+		w.visiting[ref] = true
+		defer delete(w.visiting, ref)
+		if err := w.walk(path, target); err != nil {
+			return err
+		}
+	}
+
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+		subschemas, _ := node[keyword].([]any)
+		for _, subschema := range subschemas {
+			if sub, ok := subschema.(map[string]any); ok {
+				if err := w.walk(path, sub); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if properties, ok := node["properties"].(map[string]any); ok {
+		for _, name := range sortedKeys(properties) {
+			if property, ok := properties[name].(map[string]any); ok {
+				if err := w.child(path, name, property); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if patternProperties, ok := node["patternProperties"].(map[string]any); ok {
+		for _, pattern := range sortedKeys(patternProperties) {
+			if property, ok := patternProperties[pattern].(map[string]any); ok {
+				if err := w.child(path, wildcardSegment, property); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if additionalProperties, ok := node["additionalProperties"].(map[string]any); ok {
+		if err := w.child(path, wildcardSegment, additionalProperties); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *schemaWalker) child(path []string, name string, node map[string]any) error {
+	childPath := append(append([]string{}, path...), name)
+	w.visit(childPath, node)
+	return w.walk(childPath, node)
+}
+
+// resolveLocalReference resolves a JSON pointer reference like "#/definitions/thing" within the document.
+func resolveLocalReference(document map[string]any, ref string) (map[string]any, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("only local schema references are supported, got %q", ref)
+	}
+	var current any = document
+	for _, token := range strings.Split(strings.TrimPrefix(strings.TrimPrefix(ref, "#"), "/"), "/") {
+		if token == "" {
+			continue
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("failed to resolve schema reference %q: %q does not index an object", ref, token)
+		}
+		current, ok = m[token]
+		if !ok {
+			return nil, fmt.Errorf("failed to resolve schema reference %q: %q not found", ref, token)
+		}
+	}
+	resolved, ok := current.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("failed to resolve schema reference %q: target is not a schema", ref)
+	}
+	return resolved, nil
+}
+
+// expandSchemaPath lists the concrete, dot-separated paths in the configuration which match a schema path,
+// substituting wildcard segments with every key present at that level.
+func expandSchemaPath(cfg types.Configuration, path []string) []string {
+	var paths []string
+	var expand func(current any, prefix []string, remaining []string)
+	expand = func(current any, prefix []string, remaining []string) {
+		if len(remaining) == 0 {
+			paths = append(paths, strings.Join(prefix, "."))
+			return
+		}
+		m, ok := current.(map[string]any)
+		if !ok {
+			return
+		}
+		keys := []string{remaining[0]}
+		if remaining[0] == wildcardSegment {
+			keys = sortedKeys(m)
+		}
+		for _, key := range keys {
+			value, exists := m[key]
+			if !exists {
+				continue
+			}
+			expand(value, append(append([]string{}, prefix...), key), remaining[1:])
+		}
+	}
+	expand(map[string]any(cfg), nil, path)
+	return paths
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
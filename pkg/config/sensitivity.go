@@ -0,0 +1,44 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+// SensitivityFor creates the Sensitivity for configurations resolved by the resolver: properties its service schema
+// annotates with "sensitive": true, and keys matching the key patterns, are sensitive. Configurations which are not
+// registered as using a schema only use the key patterns.
+func SensitivityFor(resolver ConfigResolver, keyPatterns []string) (*types.Sensitivity, error) {
+	document, err := resolver.SchemaDocument()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schema: %w", err)
+	}
+	var paths []string
+	if document != nil {
+		raw, err := json.Marshal(document)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal schema: %w", err)
+		}
+		paths, err = types.SensitivePathsFromSchema(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find sensitive properties in schema: %w", err)
+		}
+	}
+	return types.NewSensitivity(paths, keyPatterns)
+}
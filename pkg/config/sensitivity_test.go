@@ -0,0 +1,60 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Azure/ARO-Tools/pkg/config"
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+func TestSensitivityFor(t *testing.T) {
+	rawSchema, err := os.ReadFile("testdata/sensitivity/config.schema.json")
+	require.NoError(t, err)
+	paths, err := types.SensitivePathsFromSchema(rawSchema)
+	require.NoError(t, err)
+	require.Equal(t, []string{"clients.*.key", "geneva.certificate"}, paths)
+
+	_, err = types.SensitivePathsFromSchema([]byte(`{"properties": {"a": {"$ref": "other.json#/definitions/a"}}}`))
+	require.ErrorContains(t, err, `only local references are supported, not "other.json#/definitions/a"`)
+
+	provider, err := config.NewConfigProvider("testdata/sensitivity/config.yaml")
+	require.NoError(t, err)
+	contexts, err := config.ResolveAllContexts(provider)
+	require.NoError(t, err)
+	require.Len(t, contexts, 1)
+
+	// any ConfigResolver will do, not only the ones from this package
+	for _, resolver := range []config.ConfigResolver{contexts[0].Resolver, wrappedResolver{contexts[0].Resolver}} {
+		sensitivity, err := config.SensitivityFor(resolver, types.DefaultSensitiveKeyPatterns)
+		require.NoError(t, err)
+		require.Empty(t, cmp.Diff(types.Configuration{
+			"regionRG": contexts[0].Configuration["regionRG"],
+			"db": map[string]any{
+				"host":          "db.public.example.com",
+				"adminPassword": types.Redacted,
+			},
+			"clients": map[string]any{
+				"frontend": map[string]any{"id": "frontend", "key": types.Redacted},
+			},
+			"geneva": map[string]any{"certificate": types.Redacted},
+		}, sensitivity.Redact(contexts[0].Configuration)))
+	}
+}
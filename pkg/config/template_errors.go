@@ -0,0 +1,226 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// TemplateError describes a failure to parse or execute a template, pointing at the offending source.
+type TemplateError struct {
+	// File is the file the template was read from, if known.
+	File string
+	// Line and Column locate the failure in the source, starting from 1. Column is zero when unknown.
+	Line, Column int
+	// Snippet is the line of source where the template failed.
+	Snippet string
+	// MissingKey is the dot-separated path to a value the template references, but the variables do not hold.
+	MissingKey string
+	// Suggestions lists paths to values that the variables do hold, which are similar to the missing one.
+	Suggestions []string
+	// Err is the underlying error from text/template.
+	Err error
+}
+
+func (e *TemplateError) Error() string {
+	var location string
+	switch {
+	case e.File != "" && e.Column > 0:
+		location = fmt.Sprintf("%s:%d:%d", e.File, e.Line, e.Column)
+	case e.File != "":
+		location = fmt.Sprintf("%s:%d", e.File, e.Line)
+	case e.Column > 0:
+		location = fmt.Sprintf("line %d, column %d", e.Line, e.Column)
+	default:
+		location = fmt.Sprintf("line %d", e.Line)
+	}
+
+	msg := fmt.Sprintf("%s: %s", location, e.reason())
+	if len(e.Suggestions) > 0 {
+		msg += fmt.Sprintf(", did you mean %s?", strings.Join(e.Suggestions, " or "))
+	}
+	if e.Snippet != "" {
+		msg += "\n\t" + e.Snippet
+		if e.Column > 0 && e.Column <= len(e.Snippet)+1 {
+			// keep tabs in the indentation, so the caret lines up however they are rendered
+			indent := strings.Map(func(r rune) rune {
+				if r == '\t' {
+					return r
+				}
+				return ' '
+			}, e.Snippet[:e.Column-1])
+			msg += "\n\t" + indent + "^"
+		}
+	}
+	return msg
+}
+
+func (e *TemplateError) reason() string {
+	if e.MissingKey != "" {
+		return fmt.Sprintf("missing key %s", e.MissingKey)
+	}
+	return templateErrorReason(e.Err)
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	// execErrorPattern matches errors from executing templates, like:
+	// template: file:3:12: executing "file" at <.svc.name>: map has no entry for key "name"
+	execErrorPattern = regexp.MustCompile(`^template: .*?:(\d+):(\d+): executing "[^"]*" at <(.*)>: (.*)$`)
+	// parseErrorPattern matches errors from parsing templates, like:
+	// template: file:3: function "foo" not defined
+	parseErrorPattern = regexp.MustCompile(`^template: .*?:(\d+): (.*)$`)
+	// missingKeyPattern matches the reason for failing to execute a template when a key is missing
+	missingKeyPattern = regexp.MustCompile(`^map has no entry for key "(.*)"$`)
+	// fieldChainPattern matches simple references to values, like .svc.name
+	fieldChainPattern = regexp.MustCompile(`^(\.[a-zA-Z0-9_]+)+$`)
+)
+
+func templateErrorReason(err error) string {
+	msg := err.Error()
+	if match := execErrorPattern.FindStringSubmatch(msg); match != nil {
+		return match[4]
+	}
+	if match := parseErrorPattern.FindStringSubmatch(msg); match != nil {
+		return match[2]
+	}
+	return msg
+}
+
+// newTemplateError enriches an error from text/template with its location in the source and, for missing keys,
+// with the full path to the missing key and suggestions for what might have been meant instead. Errors that did not
+// come from text/template are returned as they are.
+func newTemplateError(file string, source []string, vars map[string]any, err error) error {
+	var execErr template.ExecError
+	isExecErr := errors.As(err, &execErr)
+	msg := err.Error()
+
+	templateErr := &TemplateError{File: file, Err: err}
+	if match := execErrorPattern.FindStringSubmatch(msg); isExecErr && match != nil {
+		templateErr.Line, _ = strconv.Atoi(match[1])
+		column, _ := strconv.Atoi(match[2])
+		templateErr.Column = column + 1
+		if keyMatch := missingKeyPattern.FindStringSubmatch(match[4]); keyMatch != nil {
+			templateErr.MissingKey = keyMatch[1]
+			if fieldChainPattern.MatchString(match[3]) {
+				templateErr.MissingKey, templateErr.Suggestions = missingKeyPath(vars, strings.Split(strings.TrimPrefix(match[3], "."), "."))
+			}
+		}
+	} else if match := parseErrorPattern.FindStringSubmatch(msg); match != nil && !isExecErr {
+		templateErr.Line, _ = strconv.Atoi(match[1])
+	} else {
+		return err
+	}
+
+	if templateErr.Line > 0 && templateErr.Line <= len(source) {
+		templateErr.Snippet = source[templateErr.Line-1]
+	}
+	if match := execErrorPattern.FindStringSubmatch(msg); match != nil && templateErr.Snippet != "" {
+		// text/template points at a node inside the expression, but the start of the expression is easier to spot
+		reported := templateErr.Column
+		if end := min(reported-1+len(match[3]), len(templateErr.Snippet)); end > 0 {
+			if index := strings.LastIndex(templateErr.Snippet[:end], match[3]); index != -1 {
+				templateErr.Column = index + 1
+			}
+		}
+	}
+	return templateErr
+}
+
+// missingKeyPath follows the fields through the variables to find the first which is missing, returning the path to it
+// along with the paths to similarly-named values which are present alongside it.
+func missingKeyPath(vars map[string]any, fields []string) (string, []string) {
+	current := vars
+	for i, field := range fields {
+		next, exists := current[field]
+		if !exists {
+			prefix := strings.Join(fields[:i], ".")
+			var suggestions []string
+			for _, candidate := range similarKeys(field, current) {
+				if prefix != "" {
+					candidate = prefix + "." + candidate
+				}
+				suggestions = append(suggestions, candidate)
+			}
+			return strings.Join(fields[:i+1], "."), suggestions
+		}
+		nested, ok := next.(map[string]any)
+		if !ok {
+			break
+		}
+		current = nested
+	}
+	return strings.Join(fields, "."), nil
+}
+
+// maxSuggestions limits how many similar keys are suggested for a missing one.
+const maxSuggestions = 3
+
+// similarKeys finds the keys of a map that are most similar to the key, ignoring those too different to be typos.
+func similarKeys(key string, m map[string]any) []string {
+	type candidate struct {
+		key      string
+		distance int
+	}
+	var candidates []candidate
+	threshold := max(2, len(key)/3)
+	for other := range m {
+		distance := editDistance(strings.ToLower(key), strings.ToLower(other))
+		if distance <= threshold {
+			candidates = append(candidates, candidate{key: other, distance: distance})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].key < candidates[j].key
+	})
+	var keys []string
+	for i := 0; i < len(candidates) && i < maxSuggestions; i++ {
+		keys = append(keys, candidates[i].key)
+	}
+	return keys
+}
+
+// editDistance computes the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
@@ -19,8 +19,37 @@ import (
 )
 
 // configurationOverrides is the internal representation for config stored on disk - we do not export it as we
-// require that users pre-process it first, which the ConfigProvider.GetResolver() will do for them.
+// require that users pre-process it first, which the ConfigProvider.GetResolver() will do for them. The layout
+// matches the latest version of the meta-schema; loaders convert older versions into it.
 type configurationOverrides struct {
+	MetaSchema string              `json:"$metaSchema,omitempty"`
+	Schema     string              `json:"$schema"`
+	Defaults   types.Configuration `json:"defaults"`
+	// key is the cloud alias
+	Overrides map[string]*cloudOverrides `json:"clouds"`
+}
+
+type cloudOverrides struct {
+	Defaults types.Configuration `json:"defaults"`
+	// key is the deploy env
+	Overrides map[string]*environmentOverrides `json:"environments"`
+}
+
+type environmentOverrides struct {
+	Defaults types.Configuration `json:"defaults"`
+	// key is the region name
+	Overrides map[string]*regionOverrides `json:"regions"`
+}
+
+type regionOverrides struct {
+	Defaults types.Configuration `json:"defaults"`
+	// key is the stamp identifier
+	Stamps map[string]types.Configuration `json:"stamps,omitempty"`
+}
+
+// configurationOverridesV1 is the on-disk layout for config files using config.meta.schema.v1.json, where
+// regions hold overrides directly and stamps cannot be configured.
+type configurationOverridesV1 struct {
 	Schema   string              `json:"$schema"`
 	Defaults types.Configuration `json:"defaults"`
 	// key is the cloud alias
@@ -34,3 +63,42 @@ type configurationOverrides struct {
 		} `json:"environments"`
 	} `json:"clouds"`
 }
+
+// convert transforms a v1 configuration into the internal representation.
+func (c configurationOverridesV1) convert() configurationOverrides {
+	out := configurationOverrides{
+		MetaSchema: metaSchemaV1Ref,
+		Schema:     c.Schema,
+		Defaults:   c.Defaults,
+	}
+	if c.Overrides == nil {
+		return out
+	}
+	out.Overrides = map[string]*cloudOverrides{}
+	for cloud, cloudCfg := range c.Overrides {
+		if cloudCfg == nil {
+			out.Overrides[cloud] = nil
+			continue
+		}
+		converted := &cloudOverrides{Defaults: cloudCfg.Defaults}
+		if cloudCfg.Overrides != nil {
+			converted.Overrides = map[string]*environmentOverrides{}
+		}
+		for environment, envCfg := range cloudCfg.Overrides {
+			if envCfg == nil {
+				converted.Overrides[environment] = nil
+				continue
+			}
+			convertedEnv := &environmentOverrides{Defaults: envCfg.Defaults}
+			if envCfg.Overrides != nil {
+				convertedEnv.Overrides = map[string]*regionOverrides{}
+			}
+			for region, regionCfg := range envCfg.Overrides {
+				convertedEnv.Overrides[region] = &regionOverrides{Defaults: regionCfg}
+			}
+			converted.Overrides[environment] = convertedEnv
+		}
+		out.Overrides[cloud] = converted
+	}
+	return out
+}
@@ -1,14 +1,15 @@
 package types
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
-	"k8s.io/apimachinery/pkg/util/sets"
-
 	"sigs.k8s.io/yaml"
 
 	"github.com/Azure/ARO-Tools/pkg/yamlwrap"
@@ -74,6 +75,35 @@ func MergeConfiguration(base, override Configuration) map[string]any {
 	return output
 }
 
+// DeepCopy returns a copy of the configuration that shares no maps or slices with the original.
+func (v Configuration) DeepCopy() Configuration {
+	if v == nil {
+		return nil
+	}
+	return deepCopyValue(map[string]any(v)).(map[string]any)
+}
+
+func deepCopyValue(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(typed))
+		for k, v := range typed {
+			out[k] = deepCopyValue(v)
+		}
+		return out
+	case Configuration:
+		return typed.DeepCopy()
+	case []any:
+		out := make([]any, len(typed))
+		for i, v := range typed {
+			out[i] = deepCopyValue(v)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
 // resolveSchemaPath resolves a schema path for a new file location while preserving whether it's relative or absolute.
 // - if the schema path is already absolute, it returns it as is
 // - if the schema path is relative, it computes a new relative path from the target file to the schema
@@ -173,7 +203,9 @@ func readAndWrapRawConfig(filePath string) (Configuration, error) {
 }
 
 // TruncateConfiguration returns a new configuration with specified paths excluded from the base configuration.
-// Paths use dot notation (e.g., "database.host", "api.endpoints.users").
+// Paths use dot notation (e.g., "database.host", "api.endpoints.users") and may contain wildcards, as described for
+// ProjectConfiguration. Exact paths which are not present in the configuration are ignored, but a wildcard path that
+// matches nothing is an error, as it is most likely a typo.
 // Returns an error if config is nil, no paths are provided, or if any path is invalid.
 func TruncateConfiguration(config Configuration, paths ...string) (map[string]any, error) {
 	if config == nil {
@@ -183,30 +215,158 @@ func TruncateConfiguration(config Configuration, paths ...string) (map[string]an
 		return nil, fmt.Errorf("no paths provided for truncation")
 	}
 
-	// Validate paths
-	for _, path := range paths {
-		if err := validateTruncatePath(path); err != nil {
-			return nil, fmt.Errorf("invalid truncate path %q: %w", path, err)
+	patterns, err := parsePathPatterns(paths, "invalid truncate path")
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make([]bool, len(patterns))
+	result := truncateConfigurationRecursive(map[string]any(config), patterns, nil, matched)
+	var unmatched []string
+	for i, pattern := range patterns {
+		if !matched[i] && !pattern.isLiteral() {
+			unmatched = append(unmatched, pattern.raw)
 		}
 	}
+	if len(unmatched) > 0 {
+		return nil, &UnmatchedPatternsError{Patterns: unmatched}
+	}
+	return result, nil
+}
+
+// ProjectConfiguration returns a new configuration holding only the values at paths which match one of the patterns,
+// along with everything below them. Patterns use dot notation, where a segment may be a wildcard: "*" matches any one
+// key and "**" matches any number of keys, including none. For instance, "svc.*.subscription" matches the subscription
+// of every service, and "acr.**" matches acr and everything below it. Wildcards never descend into lists.
+// Returns an error if config is nil, no patterns are provided, any pattern is invalid or any pattern matches nothing.
+func ProjectConfiguration(config Configuration, patterns ...string) (map[string]any, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("no patterns provided for projection")
+	}
+
+	parsed, err := parsePathPatterns(patterns, "invalid projection pattern")
+	if err != nil {
+		return nil, err
+	}
 
-	result := truncateConfigurationRecursive(map[string]any(config), sets.New(paths...), "")
+	matched := make([]bool, len(parsed))
+	result := projectConfigurationRecursive(map[string]any(config), parsed, nil, matched)
+	var unmatched []string
+	for i, pattern := range parsed {
+		if !matched[i] {
+			unmatched = append(unmatched, pattern.raw)
+		}
+	}
+	if len(unmatched) > 0 {
+		return nil, &UnmatchedPatternsError{Patterns: unmatched}
+	}
 	return result, nil
 }
 
+// UnmatchedPatternsError records the path patterns which did not match any value in a configuration.
+type UnmatchedPatternsError struct {
+	Patterns []string
+}
+
+func (e *UnmatchedPatternsError) Error() string {
+	return fmt.Sprintf("patterns matched nothing in the configuration: %s", strings.Join(e.Patterns, ", "))
+}
+
 // validPathRegex matches valid dot notation paths (one or more segments separated by dots)
 var validPathRegex = regexp.MustCompile(`^[^.]+(\.[^.]+)*$`)
 
-// validateTruncatePath validates that a path is in proper dot notation format
-func validateTruncatePath(path string) error {
+// pathPattern is a dot notation path in which a segment may be a wildcard: "*" matches any one key and "**" matches
+// any number of keys, including none.
+type pathPattern struct {
+	raw      string
+	segments []string
+}
+
+func parsePathPatterns(raw []string, description string) ([]pathPattern, error) {
+	patterns := make([]pathPattern, 0, len(raw))
+	for _, path := range raw {
+		pattern, err := parsePathPattern(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s %q: %w", description, path, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// parsePathPattern validates that a path is in proper dot notation format, with wildcards only as whole segments
+func parsePathPattern(path string) (pathPattern, error) {
 	if !validPathRegex.MatchString(path) {
-		return fmt.Errorf("path must be in dot notation format (e.g., 'key' or 'parent.child')")
+		return pathPattern{}, fmt.Errorf("path must be in dot notation format (e.g., 'key' or 'parent.child')")
+	}
+	segments := strings.Split(path, ".")
+	for _, segment := range segments {
+		if strings.Contains(segment, "*") && segment != "*" && segment != "**" {
+			return pathPattern{}, fmt.Errorf("wildcards must be whole segments, either '*' or '**', not %q", segment)
+		}
+	}
+	return pathPattern{raw: path, segments: segments}, nil
+}
+
+// isLiteral determines if the pattern holds no wildcards.
+func (p pathPattern) isLiteral() bool {
+	return !strings.Contains(p.raw, "*")
+}
+
+// matches determines if the pattern matches the path.
+func (p pathPattern) matches(path []string) bool {
+	return matchSegments(p.segments, path)
+}
+
+func matchSegments(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+	switch pattern[0] {
+	case "**":
+		for i := 0; i <= len(path); i++ {
+			if matchSegments(pattern[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	case "*":
+		return len(path) > 0 && matchSegments(pattern[1:], path[1:])
+	default:
+		return len(path) > 0 && pattern[0] == path[0] && matchSegments(pattern[1:], path[1:])
+	}
+}
+
+// matchAny records every pattern which matches the path, returning whether any did.
+func matchAny(patterns []pathPattern, path []string, matched []bool) bool {
+	found := false
+	for i, pattern := range patterns {
+		if pattern.matches(path) {
+			matched[i] = true
+			found = true
+		}
+	}
+	return found
+}
+
+// markMatches records every pattern which matches a path in the sub-tree below the value.
+func markMatches(value any, patterns []pathPattern, path []string, matched []bool) {
+	nested, ok := value.(map[string]any)
+	if !ok {
+		return
+	}
+	for key, child := range nested {
+		childPath := append(append([]string{}, path...), key)
+		matchAny(patterns, childPath, matched)
+		markMatches(child, patterns, childPath, matched)
 	}
-	return nil
 }
 
 // truncateConfigurationRecursive recursively copies the configuration while excluding specified paths
-func truncateConfigurationRecursive(current map[string]any, truncatePaths sets.Set[string], currentPath string) map[string]any {
+func truncateConfigurationRecursive(current map[string]any, patterns []pathPattern, currentPath []string, matched []bool) map[string]any {
 	if current == nil {
 		return nil
 	}
@@ -214,19 +374,15 @@ func truncateConfigurationRecursive(current map[string]any, truncatePaths sets.S
 	output := make(map[string]any)
 
 	for key, value := range current {
-		var fullPath string
-		if currentPath == "" {
-			fullPath = key
-		} else {
-			fullPath = currentPath + "." + key
-		}
+		fullPath := append(append([]string{}, currentPath...), key)
 
-		if truncatePaths.Has(fullPath) {
+		if matchAny(patterns, fullPath, matched) {
+			markMatches(value, patterns, fullPath, matched)
 			continue
 		}
 
 		if nestedMap, ok := value.(map[string]any); ok {
-			result := truncateConfigurationRecursive(nestedMap, truncatePaths, fullPath)
+			result := truncateConfigurationRecursive(nestedMap, patterns, fullPath, matched)
 			output[key] = result
 		} else {
 			// Not a nested map, copy the value as-is
@@ -236,3 +392,94 @@ func truncateConfigurationRecursive(current map[string]any, truncatePaths sets.S
 
 	return output
 }
+
+// projectConfigurationRecursive recursively copies the values at paths which match a pattern, keeping the maps that
+// lead to them
+func projectConfigurationRecursive(current map[string]any, patterns []pathPattern, currentPath []string, matched []bool) map[string]any {
+	output := make(map[string]any)
+
+	for key, value := range current {
+		fullPath := append(append([]string{}, currentPath...), key)
+
+		if matchAny(patterns, fullPath, matched) {
+			markMatches(value, patterns, fullPath, matched)
+			output[key] = deepCopyValue(value)
+			continue
+		}
+
+		if nestedMap, ok := value.(map[string]any); ok {
+			if result := projectConfigurationRecursive(nestedMap, patterns, fullPath, matched); len(result) > 0 {
+				output[key] = result
+			}
+		}
+	}
+
+	return output
+}
+
+// DecodeError records a value in a configuration which could not be decoded into the Go type for it.
+type DecodeError struct {
+	// Path is the dot path to the value, or empty for the configuration itself.
+	Path string
+	// Expected is the Go type the value was decoded into and Found describes the value.
+	Expected, Found string
+}
+
+func (e *DecodeError) Error() string {
+	path := "configuration"
+	if e.Path != "" {
+		path += "." + e.Path
+	}
+	return fmt.Sprintf("%s: cannot decode %s into %s", path, e.Found, e.Expected)
+}
+
+// Decode decodes the configuration into a Go value, like the structs generated from a service schema, as if it were
+// JSON. Values that can't be decoded are reported with a *DecodeError holding the path to them.
+func Decode(config Configuration, into any) error {
+	raw, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	if err := json.Unmarshal(raw, into); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			var document any
+			if err := json.Unmarshal(raw, &document); err != nil {
+				return fmt.Errorf("failed to decode configuration: %w", err)
+			}
+			return &DecodeError{Path: decodePath(document, typeErr.Field), Expected: typeErr.Type.String(), Found: typeErr.Value}
+		}
+		return fmt.Errorf("failed to decode configuration: %w", err)
+	}
+	return nil
+}
+
+// decodePath formats the dotted field path from the JSON decoder, writing array indices in brackets. The decoder writes
+// indices and numeric map keys alike, so the path is followed through the document to tell them apart.
+func decodePath(document any, field string) string {
+	if field == "" {
+		return ""
+	}
+	var out strings.Builder
+	current := document
+	for i, segment := range strings.Split(field, ".") {
+		switch typed := current.(type) {
+		case []any:
+			if index, err := strconv.Atoi(segment); err == nil && index >= 0 && index < len(typed) {
+				out.WriteString("[" + segment + "]")
+				current = typed[index]
+				continue
+			}
+			current = nil
+		case map[string]any:
+			current = typed[segment]
+		default:
+			current = nil
+		}
+		if i > 0 {
+			out.WriteString(".")
+		}
+		out.WriteString(segment)
+	}
+	return out.String()
+}
@@ -3,6 +3,9 @@ package types
 import (
 	"path/filepath"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
 )
 
 func TestResolveSchemaPath(t *testing.T) {
@@ -114,3 +117,140 @@ func TestResolveSchemaPath(t *testing.T) {
 		})
 	}
 }
+
+func TestProjectConfiguration(t *testing.T) {
+	cfg := Configuration{
+		"acr": map[string]any{
+			"svc":  map[string]any{"name": "arohcpsvc", "zoneRedundant": true},
+			"ocp":  map[string]any{"name": "arohcpocp"},
+			"sku":  "Premium",
+			"list": []any{map[string]any{"name": "in-a-list"}},
+		},
+		"svc": map[string]any{
+			"frontend": map[string]any{"subscription": "fe-sub", "image": "fe"},
+			"backend":  map[string]any{"subscription": "be-sub", "image": "be"},
+			"name":     "svc",
+		},
+		"dns": map[string]any{"parentZone": "example.com", "childZone": "child.example.com"},
+	}
+
+	testCases := []struct {
+		name     string
+		patterns []string
+		expected map[string]any
+		errorMsg string
+	}{
+		{
+			name:     "exact path",
+			patterns: []string{"dns.parentZone"},
+			expected: map[string]any{"dns": map[string]any{"parentZone": "example.com"}},
+		},
+		{
+			name:     "single-segment wildcard",
+			patterns: []string{"svc.*.subscription"},
+			expected: map[string]any{"svc": map[string]any{
+				"frontend": map[string]any{"subscription": "fe-sub"},
+				"backend":  map[string]any{"subscription": "be-sub"},
+			}},
+		},
+		{
+			name:     "multi-segment wildcard keeps the whole sub-tree",
+			patterns: []string{"acr.**", "acr.svc.name"},
+			expected: map[string]any{"acr": cfg["acr"]},
+		},
+		{
+			name:     "multi-segment wildcard at any depth",
+			patterns: []string{"**.name"},
+			expected: map[string]any{
+				"acr": map[string]any{
+					"svc": map[string]any{"name": "arohcpsvc"},
+					"ocp": map[string]any{"name": "arohcpocp"},
+				},
+				"svc": map[string]any{"name": "svc"},
+			},
+		},
+		{
+			name:     "no patterns",
+			errorMsg: "no patterns provided for projection",
+		},
+		{
+			name:     "invalid pattern",
+			patterns: []string{"svc..name"},
+			errorMsg: `invalid projection pattern "svc..name"`,
+		},
+		{
+			name:     "invalid wildcard",
+			patterns: []string{"svc.***"},
+			errorMsg: "wildcards must be whole segments",
+		},
+		{
+			name:     "patterns matching nothing",
+			patterns: []string{"dns.parentZone", "svc.*.password", "acr.list.name"},
+			errorMsg: "patterns matched nothing in the configuration: svc.*.password, acr.list.name",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := ProjectConfiguration(cfg, tc.patterns...)
+			if tc.errorMsg != "" {
+				require.ErrorContains(t, err, tc.errorMsg)
+				require.Nil(t, output)
+				return
+			}
+			require.NoError(t, err)
+			require.Empty(t, cmp.Diff(tc.expected, output))
+		})
+	}
+
+	projected, err := ProjectConfiguration(cfg, "acr.**")
+	require.NoError(t, err)
+	projected["acr"].(map[string]any)["sku"] = "Basic"
+	require.Equal(t, "Premium", cfg["acr"].(map[string]any)["sku"], "projection must not share maps with the original")
+}
+
+func TestDecode(t *testing.T) {
+	type image struct {
+		Registry string `json:"registry"`
+		Digest   string `json:"digest"`
+	}
+	type svc struct {
+		Replicas int     `json:"replicas"`
+		Images   []image `json:"images"`
+	}
+	type configuration struct {
+		Svc svc `json:"svc"`
+	}
+
+	var decoded configuration
+	require.NoError(t, Decode(Configuration{
+		"svc": map[string]any{
+			"replicas": 3,
+			"images":   []any{map[string]any{"registry": "arohcp.azurecr.io", "digest": "sha256:abc"}},
+			"unknown":  true,
+		},
+	}, &decoded))
+	require.Empty(t, cmp.Diff(configuration{Svc: svc{Replicas: 3, Images: []image{{Registry: "arohcp.azurecr.io", Digest: "sha256:abc"}}}}, decoded))
+
+	err := Decode(Configuration{
+		"svc": map[string]any{
+			"images": []any{map[string]any{"registry": "arohcp.azurecr.io", "digest": 42}},
+		},
+	}, &configuration{})
+	var decodeErr *DecodeError
+	require.ErrorAs(t, err, &decodeErr)
+	require.Equal(t, &DecodeError{Path: "svc.images[0].digest", Expected: "string", Found: "number"}, decodeErr)
+	require.EqualError(t, err, "configuration.svc.images[0].digest: cannot decode number into string")
+
+	// numeric map keys, like stamps, are not indices
+	type stamps struct {
+		Stamps map[string]svc `json:"stamps"`
+	}
+	err = Decode(Configuration{
+		"stamps": map[string]any{
+			"1": map[string]any{"images": []any{map[string]any{"digest": 42}}},
+		},
+	}, &stamps{})
+	require.ErrorAs(t, err, &decodeErr)
+	require.Equal(t, "stamps.1.images[0].digest", decodeErr.Path)
+}
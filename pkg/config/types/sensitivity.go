@@ -0,0 +1,304 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces sensitive values in logs and output.
+const Redacted = "<redacted>"
+
+// DefaultSensitiveKeyPatterns match the names of keys which usually hold secrets.
+var DefaultSensitiveKeyPatterns = []string{
+	`(?i)password$`,
+	`(?i)secret$`,
+	`(?i)token$`,
+	`(?i)credentials?$`,
+	`(?i)private[_-]?key$`,
+	`(?i)connection[_-]?string$`,
+	`(?i)api[_-]?key$`,
+}
+
+// Sensitivity decides which values must not be logged or printed: values at a path which matches one of the path
+// patterns, in the dot notation with wildcards described for ProjectConfiguration, and values under a key whose name
+// matches one of the key patterns. Everything below a sensitive value is sensitive, too. A nil Sensitivity treats
+// nothing as sensitive.
+type Sensitivity struct {
+	paths []pathPattern
+	keys  []*regexp.Regexp
+}
+
+// NewSensitivity creates a Sensitivity from path patterns and regular expressions for key names.
+func NewSensitivity(paths, keyPatterns []string) (*Sensitivity, error) {
+	parsed, err := parsePathPatterns(paths, "invalid sensitive path")
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*regexp.Regexp, 0, len(keyPatterns))
+	for _, pattern := range keyPatterns {
+		key, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sensitive key pattern %q: %w", pattern, err)
+		}
+		keys = append(keys, key)
+	}
+	return &Sensitivity{paths: parsed, keys: keys}, nil
+}
+
+// WithPaths returns a copy of the Sensitivity which also treats values at the path patterns as sensitive.
+func (s *Sensitivity) WithPaths(paths ...string) (*Sensitivity, error) {
+	parsed, err := parsePathPatterns(paths, "invalid sensitive path")
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return &Sensitivity{paths: parsed}, nil
+	}
+	return &Sensitivity{paths: append(append([]pathPattern{}, s.paths...), parsed...), keys: s.keys}, nil
+}
+
+// DefaultSensitivity treats values under keys matching DefaultSensitiveKeyPatterns as sensitive.
+func DefaultSensitivity() *Sensitivity {
+	sensitivity, err := NewSensitivity(nil, DefaultSensitiveKeyPatterns)
+	if err != nil {
+		panic(fmt.Sprintf("default sensitive key patterns are invalid: %v", err))
+	}
+	return sensitivity
+}
+
+// SensitivePathsFromSchema lists the path patterns to the properties a JSON schema annotates with "sensitive": true.
+// Properties under additionalProperties or patternProperties are matched with a "*" segment, items share the path of
+// their array, and local references are followed.
+func SensitivePathsFromSchema(schema []byte) ([]string, error) {
+	var root map[string]any
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schema: %w", err)
+	}
+
+	found := map[string]struct{}{}
+	var walk func(node map[string]any, path []string, visiting map[string]bool) error
+	walk = func(node map[string]any, path []string, visiting map[string]bool) error {
+		if sensitive, _ := node["sensitive"].(bool); sensitive && len(path) > 0 {
+			found[strings.Join(path, ".")] = struct{}{}
+			return nil
+		}
+		if ref, ok := node["$ref"].(string); ok {
+			if !strings.HasPrefix(ref, "#/") {
+				return fmt.Errorf("only local references are supported, not %q", ref)
+			}
+			if visiting[ref] {
+				return nil
+			}
+			target, err := resolveSchemaPointer(root, ref)
+			if err != nil {
+				return err
+			}
+			visiting[ref] = true
+			defer delete(visiting, ref)
+			if err := walk(target, path, visiting); err != nil {
+				return err
+			}
+		}
+		if properties, ok := node["properties"].(map[string]any); ok {
+			for name, property := range properties {
+				if child, ok := property.(map[string]any); ok {
+					if err := walk(child, append(append([]string{}, path...), name), visiting); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		var dynamic []map[string]any
+		if additional, ok := node["additionalProperties"].(map[string]any); ok {
+			dynamic = append(dynamic, additional)
+		}
+		if patterns, ok := node["patternProperties"].(map[string]any); ok {
+			for _, property := range patterns {
+				if child, ok := property.(map[string]any); ok {
+					dynamic = append(dynamic, child)
+				}
+			}
+		}
+		for _, child := range dynamic {
+			if err := walk(child, append(append([]string{}, path...), "*"), visiting); err != nil {
+				return err
+			}
+		}
+		if items, ok := node["items"].(map[string]any); ok {
+			if err := walk(items, path, visiting); err != nil {
+				return err
+			}
+		}
+		for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+			options, _ := node[keyword].([]any)
+			for _, option := range options {
+				if child, ok := option.(map[string]any); ok {
+					if err := walk(child, path, visiting); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(root, nil, map[string]bool{}); err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(found))
+	for path := range found {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// resolveSchemaPointer finds the schema a local reference like "#/definitions/name" points to.
+func resolveSchemaPointer(root map[string]any, ref string) (map[string]any, error) {
+	current := root
+	for _, segment := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		segment = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+		next, ok := current[segment].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("reference %q does not resolve to a schema", ref)
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// IsSensitive determines if the value at the dot notation path is sensitive.
+func (s *Sensitivity) IsSensitive(path string) bool {
+	if path == "" {
+		return false
+	}
+	return s.sensitive(strings.Split(path, "."))
+}
+
+func (s *Sensitivity) sensitive(path []string) bool {
+	if s == nil {
+		return false
+	}
+	for i := range path {
+		prefix := path[:i+1]
+		for _, pattern := range s.paths {
+			if pattern.matches(prefix) {
+				return true
+			}
+		}
+		for _, key := range s.keys {
+			if key.MatchString(path[i]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Redact returns a copy of a document, like a configuration or Helm values, in which every sensitive value is replaced
+// with Redacted. The document itself is left untouched.
+func (s *Sensitivity) Redact(value any) any {
+	return s.RedactAt("", value)
+}
+
+// RedactAt is like Redact, for a value found at the dot notation path in a document.
+func (s *Sensitivity) RedactAt(path string, value any) any {
+	var segments []string
+	if path != "" {
+		segments = strings.Split(path, ".")
+	}
+	return s.redact(value, segments)
+}
+
+func (s *Sensitivity) redact(value any, path []string) any {
+	if s.sensitive(path) {
+		return Redacted
+	}
+	switch typed := value.(type) {
+	case Configuration:
+		return Configuration(s.redactMap(typed, path))
+	case map[string]any:
+		return s.redactMap(typed, path)
+	case []any:
+		out := make([]any, len(typed))
+		for i, item := range typed {
+			out[i] = s.redact(item, path)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+func (s *Sensitivity) redactMap(value map[string]any, path []string) map[string]any {
+	if value == nil {
+		return nil
+	}
+	out := make(map[string]any, len(value))
+	for key, child := range value {
+		out[key] = s.redact(child, append(append([]string{}, path...), key))
+	}
+	return out
+}
+
+// sensitiveLineRegex matches lines which assign a value to a key in YAML, like "  key: value" or "- key: value", or to
+// a parameter in a .bicepparam file, like "param key = value", optionally after the prefix of a line in a unified diff.
+var sensitiveLineRegex = regexp.MustCompile(`^([-+ ]?\s*(?:-\s+)?(?:param\s+)?)(["']?)([A-Za-z0-9_.-]+)(["']?\s*[:=][ \t]*)(\S.*)$`)
+
+// RedactText replaces values assigned to sensitive keys in text which is not parsed, like the lines of a diff between
+// two YAML or .bicepparam files. As the path to a key isn't known, keys matching the key patterns are redacted, as are
+// keys named like the last segment of a path pattern, wherever they are; a path pattern ending in a wildcard can't be
+// matched. Values which span several lines are not found.
+func (s *Sensitivity) RedactText(text string) string {
+	if s == nil || (len(s.keys) == 0 && len(s.paths) == 0) {
+		return text
+	}
+	lines := strings.SplitAfter(text, "\n")
+	for i, line := range lines {
+		content := strings.TrimRight(line, "\r\n")
+		match := sensitiveLineRegex.FindStringSubmatch(content)
+		if match == nil {
+			continue
+		}
+		value := strings.TrimSpace(match[5])
+		if value == "|" || value == ">" || value == "{" || value == "[" {
+			continue
+		}
+		if s.sensitiveKey(match[3]) {
+			lines[i] = match[1] + match[2] + match[3] + match[4] + Redacted + line[len(content):]
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// sensitiveKey determines if a key found in text, without its path, may hold a sensitive value.
+func (s *Sensitivity) sensitiveKey(name string) bool {
+	for _, key := range s.keys {
+		if key.MatchString(name) {
+			return true
+		}
+	}
+	for _, pattern := range s.paths {
+		if last := pattern.segments[len(pattern.segments)-1]; last != "*" && last != "**" && last == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSensitivity(t *testing.T) {
+	sensitivity, err := NewSensitivity([]string{"clients.*.key"}, DefaultSensitiveKeyPatterns)
+	require.NoError(t, err)
+
+	for path, sensitive := range map[string]bool{
+		"db.host":              false,
+		"db.adminPassword":     true,
+		"db.credentials.user":  true,
+		"clients.frontend.id":  false,
+		"clients.frontend.key": true,
+		"key":                  false,
+		"":                     false,
+	} {
+		require.Equal(t, sensitive, sensitivity.IsSensitive(path), path)
+	}
+
+	values := map[string]any{
+		"db": map[string]any{
+			"host":          "db.example.com",
+			"adminPassword": "hunter2",
+			"credentials":   map[string]any{"user": "admin", "certificate": "cert"},
+		},
+		"clients": map[string]any{
+			"frontend": map[string]any{"id": "frontend", "key": "frontend-key"},
+		},
+		"replicas": []any{map[string]any{"API_KEY": "abc", "name": "a"}},
+	}
+	require.Empty(t, cmp.Diff(map[string]any{
+		"db": map[string]any{
+			"host":          "db.example.com",
+			"adminPassword": Redacted,
+			"credentials":   Redacted,
+		},
+		"clients": map[string]any{
+			"frontend": map[string]any{"id": "frontend", "key": Redacted},
+		},
+		"replicas": []any{map[string]any{"API_KEY": Redacted, "name": "a"}},
+	}, sensitivity.Redact(values)))
+	require.Equal(t, "hunter2", values["db"].(map[string]any)["adminPassword"], "the document must be left untouched")
+	require.Equal(t, Redacted, sensitivity.RedactAt("clients.frontend.key", "frontend-key"))
+	require.Equal(t, map[string]any{"id": "frontend", "key": Redacted}, sensitivity.RedactAt("clients.frontend", map[string]any{"id": "frontend", "key": "frontend-key"}))
+
+	var nothing *Sensitivity
+	require.Equal(t, values, nothing.Redact(values))
+
+	_, err = NewSensitivity(nil, []string{"("})
+	require.ErrorContains(t, err, `invalid sensitive key pattern "("`)
+	_, err = NewSensitivity([]string{"a.b*"}, nil)
+	require.ErrorContains(t, err, `invalid sensitive path "a.b*"`)
+
+	diff := `--- config.yaml
++++ config.yaml
+@@ -1,4 +1,4 @@
+ db:
+-  adminPassword: hunter2
++  adminPassword: "{{ .ctx.region }}-hunter2"
+   host: db.example.com
+ param storageConnectionString = 'secret'
+ - apiKey: abc
+ certificate: |
+     key: frontend-key
+`
+	require.Equal(t, `--- config.yaml
++++ config.yaml
+@@ -1,4 +1,4 @@
+ db:
+-  adminPassword: <redacted>
++  adminPassword: <redacted>
+   host: db.example.com
+ param storageConnectionString = <redacted>
+ - apiKey: <redacted>
+ certificate: |
+     key: <redacted>
+`, sensitivity.RedactText(diff))
+
+	extended, err := sensitivity.WithPaths("db.host")
+	require.NoError(t, err)
+	require.True(t, extended.IsSensitive("db.host"))
+	require.True(t, extended.IsSensitive("clients.frontend.key"))
+	require.False(t, sensitivity.IsSensitive("db.host"), "the original must be left untouched")
+}
@@ -0,0 +1,172 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// uniqueKeyword marks a property in the service schema as requiring a distinct value in every context of a scope.
+const uniqueKeyword = "x-unique"
+
+// UniquenessScope determines which contexts must resolve distinct values for a key marked as unique.
+type UniquenessScope string
+
+const (
+	// UniquenessScopeGlobal requires the value to be distinct across every context.
+	UniquenessScopeGlobal UniquenessScope = "global"
+	// UniquenessScopeCloud requires the value to be distinct across the contexts of each cloud.
+	UniquenessScopeCloud UniquenessScope = "cloud"
+	// UniquenessScopeEnvironment requires the value to be distinct across the contexts of each cloud and environment.
+	UniquenessScopeEnvironment UniquenessScope = "environment"
+)
+
+// uniqueness records a key in the service schema which must be unique.
+type uniqueness struct {
+	path  []string
+	scope UniquenessScope
+}
+
+// findUniqueness finds all the keys in the schema document which must be unique.
+func findUniqueness(document map[string]any) ([]uniqueness, error) {
+	var unique []uniqueness
+	var errs []string
+	if err := walkSchema(document, func(path []string, node map[string]any) {
+		raw, set := node[uniqueKeyword]
+		if !set {
+			return
+		}
+		scope, _ := raw.(string)
+		switch UniquenessScope(scope) {
+		case UniquenessScopeGlobal, UniquenessScopeCloud, UniquenessScopeEnvironment:
+			unique = append(unique, uniqueness{path: path, scope: UniquenessScope(scope)})
+		default:
+			errs = append(errs, fmt.Sprintf("%s: invalid %s scope %v, expected one of global, cloud or environment", strings.Join(path, "."), uniqueKeyword, raw))
+		}
+	}); err != nil {
+		return nil, fmt.Errorf("failed to walk schema: %w", err)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid schema annotations: %s", strings.Join(errs, "; "))
+	}
+	return unique, nil
+}
+
+// UniquenessCollision records a value that more than one context in a scope resolves for a key marked as unique.
+type UniquenessCollision struct {
+	Path  string          `json:"path"`
+	Scope UniquenessScope `json:"scope"`
+	Value string          `json:"value"`
+	// Contexts lists the colliding contexts as cloud/environment/region.
+	Contexts []string `json:"contexts"`
+}
+
+func (c UniquenessCollision) String() string {
+	return fmt.Sprintf("%s must be unique per %s, but %q is used by %s", c.Path, c.Scope, c.Value, strings.Join(c.Contexts, ", "))
+}
+
+// UniquenessError records every collision found across contexts.
+type UniquenessError struct {
+	Collisions []UniquenessCollision `json:"collisions"`
+}
+
+func (e *UniquenessError) Error() string {
+	var messages []string
+	for _, collision := range e.Collisions {
+		messages = append(messages, collision.String())
+	}
+	return fmt.Sprintf("%d unique configuration values collide:\n%s", len(e.Collisions), strings.Join(messages, "\n"))
+}
+
+// CheckUniqueness finds keys that the service schema marks with x-unique, and ensures that no two resolved contexts in
+// the same scope share a value for them. Values are compared regardless of case, like the names of Azure resources. A
+// *UniquenessError lists every collision.
+func CheckUniqueness(contexts []ResolvedContext) error {
+	if len(contexts) == 0 {
+		return nil
+	}
+	document, err := contexts[0].Resolver.SchemaDocument()
+	if err != nil {
+		return fmt.Errorf("failed to load schema: %w", err)
+	}
+	if document == nil {
+		return nil
+	}
+	unique, err := findUniqueness(document)
+	if err != nil {
+		return err
+	}
+
+	type key struct {
+		path  string
+		scope UniquenessScope
+		// within identifies the cloud or environment that the value must be unique in
+		within string
+		// value is folded to lower case, as the names of Azure resources are case-insensitive
+		value string
+	}
+	users := map[key][]string{}
+	// values records the value as the first context to use it spells it
+	values := map[key]string{}
+	var order []key
+	for _, u := range unique {
+		for _, ctx := range contexts {
+			within := ""
+			switch u.scope {
+			case UniquenessScopeCloud:
+				within = ctx.Cloud
+			case UniquenessScopeEnvironment:
+				within = ctx.Cloud + "/" + ctx.Environment
+			}
+			for _, path := range expandSchemaPath(ctx.Configuration, u.path) {
+				value, err := ctx.Configuration.GetByPath(path)
+				if err != nil {
+					return fmt.Errorf("%s: failed to get %s: %w", ctx, path, err)
+				}
+				if value == nil {
+					continue
+				}
+				original := fmt.Sprintf("%v", value)
+				k := key{path: path, scope: u.scope, within: within, value: strings.ToLower(original)}
+				if _, seen := users[k]; !seen {
+					order = append(order, k)
+					values[k] = original
+				}
+				users[k] = append(users[k], ctx.String())
+			}
+		}
+	}
+
+	uniquenessErr := &UniquenessError{}
+	for _, k := range order {
+		if len(users[k]) > 1 {
+			uniquenessErr.Collisions = append(uniquenessErr.Collisions, UniquenessCollision{
+				Path:     k.path,
+				Scope:    k.scope,
+				Value:    values[k],
+				Contexts: users[k],
+			})
+		}
+	}
+	sort.SliceStable(uniquenessErr.Collisions, func(i, j int) bool {
+		return uniquenessErr.Collisions[i].Path < uniquenessErr.Collisions[j].Path
+	})
+	if len(uniquenessErr.Collisions) > 0 {
+		return uniquenessErr
+	}
+	return nil
+}
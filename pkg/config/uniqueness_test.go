@@ -0,0 +1,91 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Azure/ARO-Tools/internal/testutil"
+	"github.com/Azure/ARO-Tools/pkg/config"
+	"github.com/Azure/ARO-Tools/pkg/config/configtest"
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+func TestCheckUniqueness(t *testing.T) {
+	schema := []byte(`{
+  "type": "object",
+  "properties": {
+    "keyVault": {"type": "string", "x-unique": "global"},
+    "resourceGroup": {"type": "string", "x-unique": "environment"},
+    "storage": {
+      "type": "object",
+      "additionalProperties": {
+        "type": "object",
+        "properties": {
+          "account": {"type": "string", "x-unique": "cloud"}
+        }
+      }
+    }
+  }
+}`)
+	provider := configtest.NewBuilder().
+		WithDefaults(types.Configuration{
+			"keyVault":      "kv-{{ .ctx.regionShort }}",
+			"resourceGroup": "hcp-{{ .ctx.region }}",
+			"storage":       map[string]any{"logs": map[string]any{"account": "logs{{ .ctx.environment }}"}},
+		}).
+		WithRegion("public", "int", "uksouth", nil).
+		WithRegion("public", "int", "eastus", types.Configuration{"resourceGroup": "hcp-uksouth"}).
+		WithRegion("public", "prod", "uksouth", types.Configuration{"keyVault": "kv-prod"}).
+		WithRegion("public", "prod", "westus", types.Configuration{"keyVault": "kv-prod"}).
+		WithRegion("ff", "int", "usgovvirginia", types.Configuration{"keyVault": "kv-ln"}).
+		WithSchema(schema).
+		MustBuild(t)
+
+	contexts, err := config.ResolveAllContexts(provider)
+	require.NoError(t, err)
+	err = config.CheckUniqueness(contexts)
+	var uniquenessErr *config.UniquenessError
+	require.ErrorAs(t, err, &uniquenessErr)
+	testutil.CompareWithFixture(t, uniquenessErr)
+
+	// resolvers wrapping the ones from the config package work, too
+	for i := range contexts {
+		contexts[i].Resolver = wrappedResolver{ConfigResolver: contexts[i].Resolver}
+	}
+	var wrappedErr *config.UniquenessError
+	require.ErrorAs(t, config.CheckUniqueness(contexts), &wrappedErr)
+	require.Equal(t, uniquenessErr, wrappedErr)
+}
+
+func TestCheckUniquenessIgnoresCase(t *testing.T) {
+	provider := configtest.NewBuilder().
+		WithDefaults(types.Configuration{"keyVault": "myVault"}).
+		WithRegion("public", "int", "uksouth", nil).
+		WithRegion("public", "int", "eastus", types.Configuration{"keyVault": "myvault"}).
+		WithSchema([]byte(`{"type": "object", "properties": {"keyVault": {"type": "string", "x-unique": "global"}}}`)).
+		MustBuild(t)
+
+	contexts, err := config.ResolveAllContexts(provider)
+	require.NoError(t, err)
+	err = config.CheckUniqueness(contexts)
+	var uniquenessErr *config.UniquenessError
+	require.ErrorAs(t, err, &uniquenessErr)
+	require.Len(t, uniquenessErr.Collisions, 1)
+	require.Equal(t, []string{"public/int/eastus", "public/int/uksouth"}, uniquenessErr.Collisions[0].Contexts)
+	require.Equal(t, "myvault", uniquenessErr.Collisions[0].Value, "the value is reported as the first context spells it")
+}
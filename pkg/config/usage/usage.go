@@ -0,0 +1,366 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package usage builds a reverse index of the configuration paths that pipelines and the files they deploy reference,
+// so that keys which nothing references can be found.
+package usage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/Azure/ARO-Tools/pkg/config"
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+	"github.com/Azure/ARO-Tools/pkg/topology"
+	"github.com/Azure/ARO-Tools/pkg/yamlwrap"
+)
+
+// Kind records how a configuration path is referenced.
+type Kind string
+
+const (
+	// KindConfigRef is a configRef field of a value in a pipeline.
+	KindConfigRef Kind = "configRef"
+	// KindProviderConfigRef is a providerConfigRef field of a feature registration step.
+	KindProviderConfigRef Kind = "providerConfigRef"
+	// KindTemplate is a template expression in a pipeline or in a file a pipeline deploys.
+	KindTemplate Kind = "template"
+)
+
+// Reference records where a configuration path is referenced.
+type Reference struct {
+	Path string `json:"path"`
+	Kind Kind   `json:"kind"`
+	File string `json:"file"`
+	// Line and Column locate the reference in the file, starting from 1.
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (r Reference) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s %s", r.File, r.Line, r.Column, r.Kind, r.Path)
+}
+
+// Index is a reverse index from configuration paths to the places that reference them.
+type Index struct {
+	references map[string][]Reference
+	indexed    sets.Set[string]
+}
+
+// NewIndex returns an empty index.
+func NewIndex() *Index {
+	return &Index{
+		references: map[string][]Reference{},
+		indexed:    sets.New[string](),
+	}
+}
+
+// IndexTopology indexes every pipeline in the topology at the given path, along with the files they deploy.
+func IndexTopology(path string) (*Index, error) {
+	topo, err := topology.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load topology %s: %w", path, err)
+	}
+	if err := topo.Validate(); err != nil {
+		return nil, fmt.Errorf("failed to validate topology %s: %w", path, err)
+	}
+
+	index := NewIndex()
+	var walk func(service topology.Service) error
+	walk = func(service topology.Service) error {
+		pipelinePath := service.PipelinePath
+		if pipelinePath == "" {
+			pipelinePath = service.Metadata["pipeline"]
+		}
+		if pipelinePath != "" {
+			if err := index.AddPipeline(filepath.Join(filepath.Dir(path), pipelinePath)); err != nil {
+				return fmt.Errorf("failed to index pipeline for %s: %w", service.ServiceGroup, err)
+			}
+		}
+		for _, child := range service.Children {
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, service := range topo.Services {
+		if err := walk(service); err != nil {
+			return nil, err
+		}
+	}
+	return index, nil
+}
+
+// AddPipeline indexes a pipeline: its configRef and providerConfigRef fields, the template expressions in it, and
+// the template expressions in the parameter, values and namespace files that it deploys.
+func (i *Index) AddPipeline(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read pipeline: %w", err)
+	}
+	if err := i.addTemplate(path, raw); err != nil {
+		return err
+	}
+
+	wrapped, err := yamlwrap.WrapYAML(raw, false)
+	if err != nil {
+		return fmt.Errorf("failed to wrap pipeline %s: %w", path, err)
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(wrapped, &document); err != nil {
+		return fmt.Errorf("failed to parse pipeline %s: %w", path, err)
+	}
+
+	// yamlwrap only rewrites values in place, so positions in the wrapped document match the raw pipeline
+	var files []string
+	walkMappings(&document, func(key, value *yaml.Node) {
+		switch key.Value {
+		case "configRef", "providerConfigRef":
+			if value.Kind == yaml.ScalarNode && value.Value != "" {
+				i.add(Reference{Path: value.Value, Kind: Kind(key.Value), File: path, Line: value.Line, Column: value.Column})
+			}
+		case "parameters", "valuesFile", "roleAssignment":
+			if value.Kind == yaml.ScalarNode && value.Value != "" {
+				files = append(files, value.Value)
+			}
+		case "namespaceFiles":
+			for _, item := range value.Content {
+				if item.Kind == yaml.ScalarNode && item.Value != "" {
+					files = append(files, item.Value)
+				}
+			}
+		}
+	})
+
+	for _, file := range files {
+		if err := i.AddTemplateFile(filepath.Join(filepath.Dir(path), file)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddTemplateFile indexes the template expressions in a file that is pre-processed with the configuration.
+func (i *Index) AddTemplateFile(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read templated file: %w", err)
+	}
+	return i.addTemplate(path, raw)
+}
+
+func (i *Index) addTemplate(path string, raw []byte) error {
+	if i.indexed.Has(path) {
+		return nil
+	}
+	i.indexed.Insert(path)
+
+	refs, err := config.ExtractTemplateReferences(raw)
+	if err != nil {
+		return fmt.Errorf("failed to extract references from %s: %w", path, err)
+	}
+	for _, ref := range refs {
+		i.add(Reference{Path: ref.Path, Kind: KindTemplate, File: path, Line: ref.Line, Column: ref.Column})
+	}
+	return nil
+}
+
+func (i *Index) add(ref Reference) {
+	i.references[ref.Path] = append(i.references[ref.Path], ref)
+}
+
+// Paths lists every referenced path, sorted.
+func (i *Index) Paths() []string {
+	paths := make([]string, 0, len(i.references))
+	for path := range i.references {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// References lists the references that use the value at the path: references to the path itself, to a parent of it
+// (which use the whole sub-tree) and to children of it.
+func (i *Index) References(path string) []Reference {
+	var out []Reference
+	for _, candidate := range i.Paths() {
+		if covers(candidate, path) {
+			out = append(out, i.references[candidate]...)
+		}
+	}
+	return out
+}
+
+// Unused lists the leaf paths defined in any of the configurations which nothing in the index references, sorted.
+// Lists are treated as leaves.
+func (i *Index) Unused(configs ...types.Configuration) []string {
+	defined := sets.New[string]()
+	for _, cfg := range configs {
+		collectLeaves(map[string]any(cfg), "", defined)
+	}
+
+	referenced := i.Paths()
+	var unused []string
+	for _, leaf := range sets.List(defined) {
+		used := false
+		for _, path := range referenced {
+			if covers(path, leaf) {
+				used = true
+				break
+			}
+		}
+		if !used {
+			unused = append(unused, leaf)
+		}
+	}
+	return unused
+}
+
+// covers determines if a reference to one path uses the value at another.
+func covers(reference, path string) bool {
+	return reference == path || strings.HasPrefix(path, reference+".") || strings.HasPrefix(reference, path+".")
+}
+
+func collectLeaves(value map[string]any, prefix string, into sets.Set[string]) {
+	for key, child := range value {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		switch typed := child.(type) {
+		case map[string]any:
+			if len(typed) == 0 {
+				into.Insert(path)
+			}
+			collectLeaves(typed, path, into)
+		case types.Configuration:
+			collectLeaves(typed, path, into)
+		default:
+			into.Insert(path)
+		}
+	}
+}
+
+// walkMappings calls visit for every key and value of every mapping in the document.
+func walkMappings(node *yaml.Node, visit func(key, value *yaml.Node)) {
+	if node.Kind == yaml.MappingNode {
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			visit(node.Content[j], node.Content[j+1])
+		}
+	}
+	for _, child := range node.Content {
+		walkMappings(child, visit)
+	}
+}
+
+// fieldChain matches a chain of fields in a template, like .svc.image or $.svc.image.
+var fieldChain = regexp.MustCompile(`^\$?((?:\.[\pL\pN_]+)+)`)
+
+// RenameReferences rewrites every reference to the path, or to a key below it, to use the new path, returning the new
+// content of each file that changed. References to a parent of the path use the whole sub-tree, and references that
+// aren't spelled as a chain of fields from the root, like `index .svc "image"`, can't be rewritten, so both are errors.
+// Fields relative to the value of a `with` block are left alone, since the block's own reference is rewritten.
+func (i *Index) RenameReferences(from, to string) (map[string][]byte, error) {
+	type edit struct {
+		Reference
+		// offset is the index into the line of the path to replace
+		offset int
+	}
+	edits := map[string][]edit{}
+	var problems []string
+	for _, path := range i.Paths() {
+		if !covers(path, from) {
+			continue
+		}
+		for _, ref := range i.references[path] {
+			if path != from && !strings.HasPrefix(path, from+".") {
+				problems = append(problems, fmt.Sprintf("%s: references %s, which holds %s", ref, path, from))
+				continue
+			}
+			edits[ref.File] = append(edits[ref.File], edit{Reference: ref})
+		}
+	}
+
+	out := map[string][]byte{}
+	for _, file := range sets.List(sets.KeySet(edits)) {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		lines := strings.SplitAfter(string(raw), "\n")
+
+		fileEdits := edits[file]
+		// edit from the end of each line so that earlier offsets stay valid
+		sort.Slice(fileEdits, func(a, b int) bool {
+			if fileEdits[a].Line != fileEdits[b].Line {
+				return fileEdits[a].Line < fileEdits[b].Line
+			}
+			return fileEdits[a].Column > fileEdits[b].Column
+		})
+		changed := false
+		for _, e := range fileEdits {
+			if e.Line < 1 || e.Line > len(lines) || e.Column < 1 || e.Column > len(lines[e.Line-1]) {
+				problems = append(problems, fmt.Sprintf("%s: position is outside of the file", e.Reference))
+				continue
+			}
+			line := lines[e.Line-1]
+			offset := e.Column - 1
+			switch e.Kind {
+			case KindConfigRef, KindProviderConfigRef:
+				if line[offset] == '"' || line[offset] == '\'' {
+					offset++
+				}
+				if !strings.HasPrefix(line[offset:], e.Path) {
+					problems = append(problems, fmt.Sprintf("%s: could not find the path in the file", e.Reference))
+					continue
+				}
+			case KindTemplate:
+				match := fieldChain.FindStringSubmatchIndex(line[offset:])
+				if match == nil {
+					problems = append(problems, fmt.Sprintf("%s: only chains of fields like .%s can be rewritten", e.Reference, e.Path))
+					continue
+				}
+				chain := strings.TrimPrefix(line[offset+match[2]:offset+match[3]], ".")
+				if chain != e.Path {
+					// a field relative to dot: fine as long as the path to dot is rewritten instead
+					if dot := strings.TrimSuffix(e.Path, "."+chain); dot != e.Path && (dot == from || strings.HasPrefix(dot, from+".")) {
+						continue
+					}
+					problems = append(problems, fmt.Sprintf("%s: only chains of fields like .%s can be rewritten", e.Reference, e.Path))
+					continue
+				}
+				offset += match[2] + 1
+			}
+			lines[e.Line-1] = line[:offset] + to + line[offset+len(from):]
+			changed = true
+		}
+		if changed {
+			out[file] = []byte(strings.Join(lines, ""))
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("cannot rename %s to %s:\n%s", from, to, strings.Join(problems, "\n"))
+	}
+	return out, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usage_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+	"github.com/Azure/ARO-Tools/pkg/config/usage"
+)
+
+func TestIndexTopology(t *testing.T) {
+	index, err := usage.IndexTopology("testdata/topology.yaml")
+	require.NoError(t, err)
+
+	if diff := cmp.Diff([]string{
+		"features.provider",
+		"owner.team",
+		"region",
+		"regionRG",
+		"svc.image.digest",
+		"svc.image.registry",
+		"svc.replicas",
+		"svc.subscription",
+		"zones",
+	}, index.Paths()); diff != "" {
+		t.Errorf("incorrect referenced paths (-want, +got): %v", diff)
+	}
+
+	if diff := cmp.Diff([]usage.Reference{
+		{Path: "svc.image.digest", Kind: usage.KindConfigRef, File: "testdata/svc/pipeline.yaml", Line: 14, Column: 18},
+		{Path: "svc.image.digest", Kind: usage.KindTemplate, File: "testdata/svc/values.yaml", Line: 1, Column: 41},
+		{Path: "svc.image.registry", Kind: usage.KindTemplate, File: "testdata/svc/values.yaml", Line: 1, Column: 11},
+	}, index.References("svc.image")); diff != "" {
+		t.Errorf("incorrect references (-want, +got): %v", diff)
+	}
+
+	unused := index.Unused(
+		types.Configuration{
+			"regionRG": "hcp-underlay",
+			"svc": map[string]any{
+				"subscription": "hcp",
+				"image":        map[string]any{"registry": "arohcp.azurecr.io", "digest": "sha256:abc", "tag": "latest"},
+				"replicas":     3,
+			},
+			"features": map[string]any{"provider": "Microsoft.ContainerService", "flags": []any{"a", "b"}},
+			"legacy":   map[string]any{"enabled": false},
+		},
+		types.Configuration{
+			"owner": map[string]any{"team": "aro", "email": "aro@example.com"},
+		},
+	)
+	if diff := cmp.Diff([]string{"features.flags", "legacy.enabled", "owner.email", "svc.image.tag"}, unused); diff != "" {
+		t.Errorf("incorrect unused keys (-want, +got): %v", diff)
+	}
+}
+
+func TestIndexMissingFile(t *testing.T) {
+	index := usage.NewIndex()
+	require.ErrorContains(t, index.AddPipeline("testdata/missing.yaml"), "failed to read pipeline")
+}
+
+func TestRenameReferences(t *testing.T) {
+	index, err := usage.IndexTopology("testdata/topology.yaml")
+	require.NoError(t, err)
+
+	renamed, err := index.RenameReferences("svc.image", "images.svc")
+	require.NoError(t, err)
+	if diff := cmp.Diff(map[string]string{
+		"testdata/svc/pipeline.yaml": strings.Replace(readFile(t, "testdata/svc/pipeline.yaml"), "configRef: svc.image.digest", "configRef: images.svc.digest", 1),
+		"testdata/svc/values.yaml": `image: {{ .images.svc.registry }}/svc@{{ .images.svc.digest }}
+replicas: {{ .svc.replicas }}
+`,
+	}, asStrings(renamed)); diff != "" {
+		t.Errorf("incorrect renamed files (-want, +got): %v", diff)
+	}
+
+	_, err = index.RenameReferences("svc.subscription.key", "subscriptionKey")
+	require.ErrorContains(t, err, "testdata/svc/cluster.yaml:7:20: template svc.subscription: references svc.subscription, which holds svc.subscription.key")
+}
+
+func readFile(t *testing.T, path string) string {
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(raw)
+}
+
+func asStrings(files map[string][]byte) map[string]string {
+	out := map[string]string{}
+	for file, content := range files {
+		out[file] = string(content)
+	}
+	return out
+}
@@ -0,0 +1,162 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/Azure/ARO-Tools/pkg/config/ev2config"
+)
+
+// ContextFailure records that a configuration template could not be processed for a context.
+type ContextFailure struct {
+	Cloud       string
+	Environment string
+	Region      string
+	// Stamp is empty when the template failed without a stamp.
+	Stamp string
+	Err   error
+}
+
+func (f ContextFailure) String() string {
+	context := fmt.Sprintf("%s/%s/%s", f.Cloud, f.Environment, f.Region)
+	if f.Stamp != "" {
+		context += fmt.Sprintf(" (stamp %s)", f.Stamp)
+	}
+	return fmt.Sprintf("%s: %v", context, f.Err)
+}
+
+// TemplateVerificationError records every context for which a configuration template could not be processed.
+type TemplateVerificationError struct {
+	Failures []ContextFailure
+}
+
+func (e *TemplateVerificationError) Error() string {
+	var messages []string
+	for _, failure := range e.Failures {
+		messages = append(messages, failure.String())
+	}
+	return fmt.Sprintf("configuration template failed for %d contexts:\n%s", len(e.Failures), strings.Join(messages, "\n"))
+}
+
+// VerifyTemplate processes a configuration template for every cloud and region in the Ev2 central configuration,
+// validating each result against the configuration meta-schema. Each pair is processed for every environment the
+// configuration declares in any cloud, and for every stamp the configuration declares for the region in that
+// environment, as well as without a stamp. Templates are otherwise only processed with one fake context when the
+// provider is created, so expressions which fail for other contexts would only be found at deploy time. The
+// environments and stamps are found by processing the template with that fake context, so it must succeed. A
+// *TemplateVerificationError lists every context that fails.
+func VerifyTemplate(raw []byte) error {
+	tmpl, err := parseTemplate("", raw)
+	if err != nil {
+		return err
+	}
+	fakeReplacements, err := NewConfigReplacements("public", "int", "uksouth", "1")
+	if err != nil {
+		return err
+	}
+	rawContent, err := tmpl.execute(fakeReplacements.AsMap())
+	if err != nil {
+		return err
+	}
+	layout, err := loadConfigurationOverrides(rawContent)
+	if err != nil {
+		return err
+	}
+	return verifyTemplate(tmpl, layout)
+}
+
+// verifyTemplate processes the template for every context, using the environments and stamps declared in the layout
+// of the configuration.
+func verifyTemplate(tmpl *parsedTemplate, layout configurationOverrides) error {
+	contexts, err := ev2config.AllContexts()
+	if err != nil {
+		return fmt.Errorf("failed to get ev2 contexts: %w", err)
+	}
+
+	declared := map[string]struct{}{}
+	for _, cloudCfg := range layout.Overrides {
+		if cloudCfg == nil {
+			continue
+		}
+		for environment := range cloudCfg.Overrides {
+			declared[environment] = struct{}{}
+		}
+	}
+	environments := sortedKeys(declared)
+	if len(environments) == 0 {
+		// a configuration without environments is still processed for every cloud and region
+		environments = []string{""}
+	}
+
+	verificationErr := &TemplateVerificationError{}
+	for _, cloud := range sortedKeys(contexts) {
+		regions := append([]string{}, contexts[cloud]...)
+		sort.Strings(regions)
+		for _, environment := range environments {
+			for _, region := range regions {
+				for _, stamp := range declaredStamps(layout, cloud, environment, region) {
+					if err := verifyTemplateForContext(tmpl, cloud, environment, region, stamp); err != nil {
+						verificationErr.Failures = append(verificationErr.Failures, ContextFailure{Cloud: cloud, Environment: environment, Region: region, Stamp: stamp, Err: err})
+					}
+				}
+			}
+		}
+	}
+	if len(verificationErr.Failures) > 0 {
+		return verificationErr
+	}
+	return nil
+}
+
+// declaredStamps lists the empty stamp, followed by the stamps the configuration declares for the region, sorted.
+func declaredStamps(layout configurationOverrides, cloud, environment, region string) []string {
+	stamps := []string{""}
+	cloudCfg, ok := layout.Overrides[cloud]
+	if !ok || cloudCfg == nil {
+		return stamps
+	}
+	envCfg, ok := cloudCfg.Overrides[environment]
+	if !ok || envCfg == nil {
+		return stamps
+	}
+	regionCfg, ok := envCfg.Overrides[region]
+	if !ok || regionCfg == nil {
+		return stamps
+	}
+	return append(stamps, sortedKeys(regionCfg.Stamps)...)
+}
+
+func verifyTemplateForContext(tmpl *parsedTemplate, cloud, environment, region, stamp string) error {
+	replacements, err := NewConfigReplacements(cloud, environment, region, stamp)
+	if err != nil {
+		return err
+	}
+
+	rawContent, err := tmpl.execute(replacements.AsMap())
+	if err != nil {
+		return err
+	}
+
+	forValidation := map[string]any{}
+	if err := yaml.Unmarshal(rawContent, &forValidation); err != nil {
+		return fmt.Errorf("failed to unmarshal processed template: %w", err)
+	}
+	return ValidateConfigMetaSchema(forValidation)
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Azure/ARO-Tools/pkg/config"
+)
+
+func TestVerifyTemplate(t *testing.T) {
+	raw, err := os.ReadFile("../../testdata/config.yaml")
+	require.NoError(t, err)
+	require.NoError(t, config.VerifyTemplate(raw))
+
+	broken := []byte(`defaults:
+  name: {{ if eq .ctx.region "usdodeast" }}{{ .ctx.missing }}{{ else }}valid{{ end }}
+{{- if eq .ctx.region "eastus" }}
+bogus: true
+{{- end }}
+`)
+	err = config.VerifyTemplate(broken)
+	var verificationErr *config.TemplateVerificationError
+	require.ErrorAs(t, err, &verificationErr)
+
+	var failed []string
+	for _, failure := range verificationErr.Failures {
+		failed = append(failed, failure.Cloud+"/"+failure.Region)
+	}
+	require.Equal(t, []string{"ff/usdodeast", "public/eastus"}, failed)
+	require.ErrorContains(t, verificationErr.Failures[0].Err, "missing key ctx.missing")
+	require.ErrorContains(t, verificationErr.Failures[1].Err, "bogus")
+
+	_, err = config.NewConfigProviderFromData(broken, "", config.WithTemplateVerification())
+	require.ErrorAs(t, err, &verificationErr)
+}
+
+func TestVerifyTemplateEnvironmentsAndStamps(t *testing.T) {
+	raw := []byte(`$metaSchema: config.meta.schema.v2.json
+defaults:
+  name: {{ if and (eq .ctx.environment "prod") (eq .ctx.region "westus3") }}{{ .ctx.missing }}{{ else }}valid{{ end }}
+  stamped: {{ if eq .ctx.stamp "2" }}{{ .ctx.unstamped }}{{ else }}valid{{ end }}
+clouds:
+  public:
+    environments:
+      int:
+        regions:
+          uksouth:
+            stamps:
+              "1": {}
+              "2": {}
+      prod:
+        regions:
+          westus3: {}
+`)
+	err := config.VerifyTemplate(raw)
+	var verificationErr *config.TemplateVerificationError
+	require.ErrorAs(t, err, &verificationErr)
+
+	var failed []string
+	for _, failure := range verificationErr.Failures {
+		failed = append(failed, strings.Join([]string{failure.Cloud, failure.Environment, failure.Region, failure.Stamp}, "/"))
+	}
+	require.Equal(t, []string{"public/int/uksouth/2", "public/prod/westus3/"}, failed)
+	require.ErrorContains(t, err, "public/int/uksouth (stamp 2): ")
+	require.ErrorContains(t, err, "public/prod/westus3: ")
+}
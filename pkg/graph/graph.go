@@ -144,6 +144,12 @@ func ForEntrypoints(topo *topology.Topology, entrypoints []*topology.Entrypoint,
 		}
 	}
 
+	if topo.ConfigScopes != nil {
+		if err := graph.checkConfigScopes(topo.ConfigScopes, pipelines); err != nil {
+			return nil, err
+		}
+	}
+
 	// External step dependencies break the nice separation between nodes of one pipeline and the rest of the graph,
 	// so the `nodesFor()` method can no longer generate bi-directional edges as it does not see other nodes to add
 	// child relations. Instead of trying to teach `nodesFor()` how to do half of these edges, we can just do a pass
@@ -155,6 +161,30 @@ func ForEntrypoints(topo *topology.Topology, entrypoints []*topology.Entrypoint,
 	return graph, graph.detectCycles()
 }
 
+// checkConfigScopes ensures that the pipeline for every service in the graph only references configuration that its
+// service group may read. Pipelines have already been pre-processed, so only configRef fields can be checked here;
+// template expressions are checked when loading a pipeline with types.WithConfigScopes.
+func (c *Graph) checkConfigScopes(scopes *topology.ConfigScopes, pipelines map[string]*types.Pipeline) error {
+	var violations []topology.ScopeViolation
+	for _, serviceGroup := range slices.Sorted(maps.Keys(c.Services)) {
+		pipeline, ok := pipelines[serviceGroup]
+		if !ok {
+			continue
+		}
+		refs, err := pipeline.ConfigReferences()
+		if err != nil {
+			return fmt.Errorf("failed to list configuration references for %s: %w", serviceGroup, err)
+		}
+		for _, ref := range refs {
+			violations = append(violations, scopes.Check(serviceGroup, ref.Path, ref.Field)...)
+		}
+	}
+	if len(violations) > 0 {
+		return &topology.ScopeError{Violations: violations}
+	}
+	return nil
+}
+
 // accumulate recursively traverses the service and all children, building a graph of how steps in each service depend on each other.
 func (c *Graph) accumulate(service *topology.Service, pipelines map[string]*types.Pipeline) error {
 	if _, alreadyRecorded := c.Services[service.ServiceGroup]; alreadyRecorded {
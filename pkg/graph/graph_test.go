@@ -3,6 +3,7 @@ package graph
 import (
 	"bytes"
 	"embed"
+	"errors"
 	"fmt"
 	"os/exec"
 	"path/filepath"
@@ -10,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/goccy/go-graphviz"
+	"github.com/google/go-cmp/cmp"
 
 	"sigs.k8s.io/yaml"
 
@@ -161,3 +163,46 @@ func loadPipelines(root *topology.Service, pipelines map[string]*types.Pipeline)
 	}
 	return nil
 }
+
+func TestForEntrypointConfigScopes(t *testing.T) {
+	topo, entrypoint, _, pipelines := loadTestdata(t, "topology.yaml")
+	topo.ConfigScopes = &topology.ConfigScopes{
+		Owners: map[string][]string{
+			"Microsoft.Azure.ARO.HCP.Grandparent": {"acr"},
+			"Microsoft.Azure.ARO.HCP.Child":       {"svc"},
+			"Microsoft.Azure.ARO.HCP.Sibling":     {"mgmt"},
+		},
+		Reads: map[string][]string{
+			"Microsoft.Azure.ARO.HCP.Parent": {"acr.ocp"},
+		},
+	}
+	if err := topo.Validate(); err != nil {
+		t.Fatalf("Failed to validate topology: %v", err)
+	}
+
+	_, err := ForEntrypoint(topo, entrypoint, pipelines)
+	var scopeErr *topology.ScopeError
+	if !errors.As(err, &scopeErr) {
+		t.Fatalf("expected configuration scope error, got %v", err)
+	}
+	if diff := cmp.Diff([]topology.ScopeViolation{{
+		ServiceGroup: "Microsoft.Azure.ARO.HCP.Parent",
+		Path:         "acr.svc.name",
+		Source:       "resourceGroups[0].steps[2].variables[0].configRef",
+		Owner:        "Microsoft.Azure.ARO.HCP.Grandparent",
+		OwnedPath:    "acr",
+	}, {
+		ServiceGroup: "Microsoft.Azure.ARO.HCP.Parent",
+		Path:         "svc.subscription.providers",
+		Source:       "resourceGroups[1].steps[0].providerConfigRef",
+		Owner:        "Microsoft.Azure.ARO.HCP.Child",
+		OwnedPath:    "svc",
+	}}, scopeErr.Violations); diff != "" {
+		t.Errorf("incorrect violations (-want, +got): %v", diff)
+	}
+
+	topo.ConfigScopes.Shared = []string{"acr.svc", "svc.subscription"}
+	if _, err := ForEntrypoint(topo, entrypoint, pipelines); err != nil {
+		t.Fatalf("Failed to create graph for entrypoint with shared configuration: %v", err)
+	}
+}
@@ -39,11 +39,13 @@ import (
 	"sigs.k8s.io/yaml"
 
 	"github.com/Azure/ARO-Tools/pkg/cmdutils"
+	"github.com/Azure/ARO-Tools/pkg/config/types"
 )
 
 func DefaultOptions() *RawOptions {
 	return &RawOptions{
-		Timeout: 5 * time.Minute,
+		Timeout:              5 * time.Minute,
+		SensitiveKeyPatterns: types.DefaultSensitiveKeyPatterns,
 	}
 }
 
@@ -60,6 +62,9 @@ func BindOptions(opts *RawOptions, cmd *cobra.Command) error {
 	cmd.Flags().StringVar(&opts.KustoDatabase, "kusto-database", opts.KustoDatabase, "Name of the Kusto database in the given cluster to use for diagnostics.")
 	cmd.Flags().StringVar(&opts.KustoTable, "kusto-table", opts.KustoTable, "Name of the Kusto table in the given database to use for diagnostics.")
 
+	cmd.Flags().StringSliceVar(&opts.SensitivePaths, "sensitive-path", opts.SensitivePaths, "Path to a value, in dot notation with '*' and '**' wildcards, which is redacted when values are logged.")
+	cmd.Flags().StringSliceVar(&opts.SensitiveKeyPatterns, "sensitive-key-pattern", opts.SensitiveKeyPatterns, "Regular expression for the names of keys whose values are redacted when values are logged.")
+
 	cmd.Flags().DurationVar(&opts.Timeout, "timeout", opts.Timeout, "Timeout for waiting on the Helm release.")
 
 	cmd.Flags().StringVar(&opts.KubeconfigFile, "kubeconfig", opts.KubeconfigFile, "Path to the kubeconfig.")
@@ -83,6 +88,9 @@ type RawOptions struct {
 	KustoDatabase string
 	KustoTable    string
 
+	SensitivePaths       []string
+	SensitiveKeyPatterns []string
+
 	Timeout time.Duration
 
 	KubeconfigFile    string
@@ -121,6 +129,9 @@ type completedOptions struct {
 	KustoDatabase string
 	KustoTable    string
 
+	// Sensitivity redacts values before they are logged.
+	Sensitivity *types.Sensitivity
+
 	Timeout           time.Duration
 	DryRun            bool
 	RollbackOnFailure bool
@@ -156,6 +167,11 @@ func (o *RawOptions) Validate() (*ValidatedOptions, error) {
 }
 
 func (o *ValidatedOptions) Complete() (*Options, error) {
+	sensitivity, err := types.NewSensitivity(o.SensitivePaths, o.SensitiveKeyPatterns)
+	if err != nil {
+		return nil, err
+	}
+
 	rawConfig, err := clientcmd.LoadFromFile(o.KubeconfigFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
@@ -251,6 +267,8 @@ func (o *ValidatedOptions) Complete() (*Options, error) {
 			KustoDatabase: o.KustoDatabase,
 			KustoTable:    o.KustoTable,
 
+			Sensitivity: sensitivity,
+
 			Timeout:           o.Timeout,
 			DryRun:            o.DryRun,
 			RollbackOnFailure: o.RollbackOnFailure,
@@ -264,7 +282,7 @@ func (opts *Options) Deploy(ctx context.Context) error {
 		return fmt.Errorf("failed to create logger: %w", err)
 	}
 
-	logger.Info("Resolved input values.", "values", opts.Values)
+	logger.Info("Resolved input values.", "values", opts.Sensitivity.Redact(opts.Values))
 
 	logger.Info("Applying namespaces.")
 	// Helm does not let us manage namespaces easily, so we need to apply them ourselves, up-front.
@@ -427,7 +445,7 @@ func runDiagnostics(ctx context.Context, logger logr.Logger, opts *Options, depl
 		"namespace", release.Namespace,
 		"status", release.Info.Status,
 		"description", release.Info.Description,
-		"values", release.Config,
+		"values", opts.Sensitivity.Redact(release.Config),
 	)
 
 	if release.Info == nil || len(release.Info.Resources) == 0 {
@@ -560,7 +578,7 @@ func validateHelmResources(ctx context.Context, logger logr.Logger, opts *Option
 				objLogger.Error(err, "Failed to fetch current resource state for diffing.")
 			}
 			objLogger.Info("Printing diff between live object and intended manifest on disk.")
-			fmt.Println(cmp.Diff(current, obj))
+			fmt.Println(cmp.Diff(redactObject(opts.Sensitivity, current), redactObject(opts.Sensitivity, obj)))
 		} else {
 			objLogger.Info("Validated resource using server-side dry-run.")
 		}
@@ -571,6 +589,30 @@ func validateHelmResources(ctx context.Context, logger logr.Logger, opts *Option
 	return nil
 }
 
+// redactObject returns a copy of an object that is safe to print: the data of a Secret, along with the copy of it that
+// client-side apply records in an annotation, and values the sensitivity matches are redacted.
+func redactObject(sensitivity *types.Sensitivity, obj *unstructured.Unstructured) *unstructured.Unstructured {
+	if obj == nil {
+		return nil
+	}
+	redacted := obj.DeepCopy()
+	if gvk := redacted.GroupVersionKind(); gvk.Group == "" && gvk.Kind == "Secret" {
+		for _, field := range []string{"data", "stringData"} {
+			if data, ok := redacted.Object[field].(map[string]any); ok {
+				for key := range data {
+					data[key] = types.Redacted
+				}
+			}
+		}
+		if annotations := redacted.GetAnnotations(); annotations[corev1.LastAppliedConfigAnnotation] != "" {
+			annotations[corev1.LastAppliedConfigAnnotation] = types.Redacted
+			redacted.SetAnnotations(annotations)
+		}
+	}
+	redacted.Object = sensitivity.Redact(redacted.Object).(map[string]any)
+	return redacted
+}
+
 // getManagedFieldsManager follows the (bizarre) mechanism that Helm uses to figure out the field manager
 // see: https://github.com/helm/helm/blob/0adfe83ff8a46630164388c71620818e11253ece/pkg/kube/client.go#L838-L846
 func getManagedFieldsManager() string {
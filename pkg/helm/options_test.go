@@ -0,0 +1,122 @@
+package helm
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"helm.sh/helm/v4/pkg/action"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	helmreleasecommon "helm.sh/helm/v4/pkg/release/common"
+	helmreleasev1 "helm.sh/helm/v4/pkg/release/v1"
+	"helm.sh/helm/v4/pkg/storage"
+	"helm.sh/helm/v4/pkg/storage/driver"
+
+	"github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+func TestRunDiagnosticsRedactsValues(t *testing.T) {
+	sensitivity, err := types.NewSensitivity([]string{"geneva.certificate"}, types.DefaultSensitiveKeyPatterns)
+	if err != nil {
+		t.Fatalf("failed to create sensitivity: %v", err)
+	}
+
+	releases := storage.Init(driver.NewMemory())
+	if err := releases.Create(&helmreleasev1.Release{
+		Name:      "test",
+		Namespace: "test-namespace",
+		Version:   1,
+		Info:      &helmreleasev1.Info{Status: helmreleasecommon.StatusDeployed},
+		Config: map[string]any{
+			"image": "arohcp.azurecr.io/test@sha256:abc",
+			"db": map[string]any{
+				"host":          "db.example.com",
+				"adminPassword": "hunter2",
+			},
+			"geneva": map[string]any{
+				"certificate": "geneva-certificate",
+			},
+			"sidecars": []any{
+				map[string]any{"name": "proxy", "token": "sidecar-token"},
+			},
+		},
+	}); err != nil {
+		t.Fatalf("failed to create release: %v", err)
+	}
+
+	var logs strings.Builder
+	logger := funcr.New(func(prefix, args string) {
+		logs.WriteString(args + "\n")
+	}, funcr.Options{Verbosity: 10})
+
+	opts := &Options{completedOptions: &completedOptions{
+		ActionConfig: &action.Configuration{
+			Releases:   releases,
+			KubeClient: &kubefake.PrintingKubeClient{Out: io.Discard, LogOutput: io.Discard},
+		},
+		ReleaseName:      "test",
+		ReleaseNamespace: "test-namespace",
+		Sensitivity:      sensitivity,
+	}}
+	if err := runDiagnostics(context.Background(), logger, opts, time.Now()); err != nil {
+		t.Fatalf("failed to run diagnostics: %v", err)
+	}
+
+	for _, secret := range []string{"hunter2", "geneva-certificate", "sidecar-token"} {
+		if strings.Contains(logs.String(), secret) {
+			t.Errorf("sensitive value %q reached the logger:\n%s", secret, logs.String())
+		}
+	}
+	for _, expected := range []string{"db.example.com", "arohcp.azurecr.io/test@sha256:abc", types.Redacted} {
+		if !strings.Contains(logs.String(), expected) {
+			t.Errorf("expected %q to be logged:\n%s", expected, logs.String())
+		}
+	}
+}
+
+func TestRedactObject(t *testing.T) {
+	sensitivity, err := types.NewSensitivity(nil, types.DefaultSensitiveKeyPatterns)
+	if err != nil {
+		t.Fatalf("failed to create sensitivity: %v", err)
+	}
+
+	secret := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]any{
+			"name":        "db",
+			"namespace":   "test-namespace",
+			"annotations": map[string]any{corev1.LastAppliedConfigAnnotation: `{"data":{"password":"aHVudGVyMg=="}}`},
+		},
+		"data":       map[string]any{"password": "aHVudGVyMg=="},
+		"stringData": map[string]any{"user": "admin"},
+	}}
+	configMap := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]any{"name": "settings", "namespace": "test-namespace"},
+		"data":       map[string]any{"host": "db.example.com", "apiKey": "sidecar-key"},
+	}}
+
+	diff := cmp.Diff(redactObject(sensitivity, nil), redactObject(sensitivity, secret)) + cmp.Diff(redactObject(sensitivity, configMap), redactObject(sensitivity, secret))
+	for _, value := range []string{"aHVudGVyMg==", "admin", "sidecar-key"} {
+		if strings.Contains(diff, value) {
+			t.Errorf("sensitive value %q reached the diff:\n%s", value, diff)
+		}
+	}
+	for _, expected := range []string{"password", "db.example.com", types.Redacted} {
+		if !strings.Contains(diff, expected) {
+			t.Errorf("expected %q in the diff:\n%s", expected, diff)
+		}
+	}
+	if secret.Object["data"].(map[string]any)["password"] != "aHVudGVyMg==" {
+		t.Errorf("redacting must not modify the object")
+	}
+}
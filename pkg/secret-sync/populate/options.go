@@ -117,27 +117,15 @@ func (o *ValidatedOptions) Complete() (*Options, error) {
 		ev2Cloud = cmdutils.RolloutCloudPublic
 	}
 
-	region, err := ev2config.GetDefaultRegionForCloud(ev2Cloud)
-	if err != nil {
-		return nil, err
-	}
-
-	ev2Cfg, err := ev2config.ResolveConfig(string(ev2Cloud), region)
+	cloud, err := ev2config.Cloud(string(ev2Cloud))
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve ev2 config for %s: %w", ev2Cloud, err)
 	}
 
-	rawKeyVaultDNSSuffix, err := ev2Cfg.GetByPath("keyVault.domainNameSuffix")
-	if err != nil {
-		return nil, fmt.Errorf("failed to resolve keyVault DNS suffix: %w", err)
-	}
-
-	keyVaultDNSSuffix, ok := rawKeyVaultDNSSuffix.(string)
-	if !ok {
-		return nil, fmt.Errorf("keyVault DNS suffix was %T, not string", rawKeyVaultDNSSuffix)
+	if cloud.KeyVaultSuffix() == "" {
+		return nil, fmt.Errorf("the ev2 config for %s has no keyVault.domainNameSuffix to build the key vault URI with", ev2Cloud)
 	}
-
-	keyVaultURI := fmt.Sprintf("https://%s.%s", o.KeyVault, keyVaultDNSSuffix)
+	keyVaultURI := fmt.Sprintf("https://%s.%s", o.KeyVault, cloud.KeyVaultSuffix())
 
 	keyVaultCfg, exists := cfg.KeyVaults[keyVaultURI]
 	if !exists {
@@ -0,0 +1,180 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topology
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ConfigScopes declares which service groups own which sub-trees of the service configuration. A service group may
+// reference configuration that nobody owns, that it owns, or that is shared with it; references into sub-trees that
+// another service group owns are violations. Sub-trees are dot paths, like svc.frontend, and hold every key below them.
+type ConfigScopes struct {
+	// Owners maps each service group to the configuration sub-trees it owns.
+	Owners map[string][]string `json:"owners,omitempty"`
+
+	// Shared lists sub-trees that any service group may read, even though another owns them.
+	Shared []string `json:"shared,omitempty"`
+
+	// Reads maps each service group to the sub-trees owned by others that it is explicitly allowed to read.
+	Reads map[string][]string `json:"reads,omitempty"`
+}
+
+// ScopeViolation records a reference from a service group into a configuration sub-tree that another owns.
+type ScopeViolation struct {
+	ServiceGroup string `json:"serviceGroup"`
+	// Path is the referenced configuration path.
+	Path string `json:"path"`
+	// Source describes where the reference is made, like a file and position, or a step.
+	Source string `json:"source,omitempty"`
+
+	// Owner is the service group owning OwnedPath, which Path reads.
+	Owner     string `json:"owner"`
+	OwnedPath string `json:"ownedPath"`
+}
+
+func (v ScopeViolation) String() string {
+	var source string
+	if v.Source != "" {
+		source = v.Source + ": "
+	}
+	return fmt.Sprintf("%s%s references %s, but %s is owned by %s", source, v.ServiceGroup, v.Path, v.OwnedPath, v.Owner)
+}
+
+// ScopeError records every reference into configuration that the referencing service group may not read.
+type ScopeError struct {
+	Violations []ScopeViolation
+}
+
+func (e *ScopeError) Error() string {
+	var lines []string
+	for _, violation := range e.Violations {
+		lines = append(lines, violation.String())
+	}
+	return fmt.Sprintf("configuration referenced out of scope:\n%s", strings.Join(lines, "\n"))
+}
+
+// Check determines if the service group may reference the configuration path, returning a violation for every
+// sub-tree owned by another service group that the path reads. Reading a parent of an owned sub-tree reads the
+// sub-tree, too.
+func (s *ConfigScopes) Check(serviceGroup, path, source string) []ScopeViolation {
+	if s == nil {
+		return nil
+	}
+	var violations []ScopeViolation
+	for _, owner := range sortedOwners(s.Owners) {
+		if owner == serviceGroup {
+			continue
+		}
+		for _, owned := range s.Owners[owner] {
+			// the part of the configuration read by the path and owned by another
+			var overlap string
+			switch {
+			case within(path, owned):
+				overlap = path
+			case within(owned, path):
+				overlap = owned
+			default:
+				continue
+			}
+			if s.readable(serviceGroup, overlap) {
+				continue
+			}
+			violations = append(violations, ScopeViolation{
+				ServiceGroup: serviceGroup,
+				Path:         path,
+				Source:       source,
+				Owner:        owner,
+				OwnedPath:    owned,
+			})
+		}
+	}
+	return violations
+}
+
+// readable determines if the sub-tree is shared, or explicitly readable by the service group.
+func (s *ConfigScopes) readable(serviceGroup, path string) bool {
+	for _, allowed := range append(append([]string{}, s.Shared...), s.Reads[serviceGroup]...) {
+		if within(path, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// within determines if the path is the sub-tree or below it.
+func within(path, subtree string) bool {
+	return path == subtree || strings.HasPrefix(path, subtree+".")
+}
+
+func sortedOwners(owners map[string][]string) []string {
+	out := make([]string, 0, len(owners))
+	for owner := range owners {
+		out = append(out, owner)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// validate ensures that the scopes refer to service groups in the topology, that paths are well-formed and that no
+// sub-tree has more than one owner. A service group may own nested sub-trees, like svc and svc.frontend.
+func (s *ConfigScopes) validate(serviceGroups sets.Set[string]) []string {
+	var messages []string
+	checkPaths := func(field string, paths []string) {
+		for _, path := range paths {
+			if path == "" || strings.HasPrefix(path, ".") || strings.HasSuffix(path, ".") || strings.Contains(path, "..") {
+				messages = append(messages, fmt.Sprintf("configScopes.%s: path %q must be in dot notation format", field, path))
+			}
+		}
+	}
+	for _, field := range []struct {
+		name   string
+		groups map[string][]string
+	}{
+		{name: "owners", groups: s.Owners},
+		{name: "reads", groups: s.Reads},
+	} {
+		for _, serviceGroup := range sortedOwners(field.groups) {
+			if !serviceGroups.Has(serviceGroup) {
+				messages = append(messages, fmt.Sprintf("configScopes.%s: service group %s was not found in the dependency tree", field.name, serviceGroup))
+			}
+			checkPaths(field.name+"["+serviceGroup+"]", field.groups[serviceGroup])
+		}
+	}
+	checkPaths("shared", s.Shared)
+
+	type ownership struct{ owner, path string }
+	var owned []ownership
+	for _, owner := range sortedOwners(s.Owners) {
+		for _, path := range s.Owners[owner] {
+			owned = append(owned, ownership{owner: owner, path: path})
+		}
+	}
+	for i, a := range owned {
+		for _, b := range owned[i+1:] {
+			if a.owner == b.owner {
+				continue
+			}
+			if within(a.path, b.path) || within(b.path, a.path) {
+				messages = append(messages, fmt.Sprintf("configScopes.owners: %s owned by %s overlaps %s owned by %s", a.path, a.owner, b.path, b.owner))
+			}
+		}
+	}
+	return messages
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topology
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestConfigScopesCheck(t *testing.T) {
+	scopes := &ConfigScopes{
+		Owners: map[string][]string{
+			"Microsoft.Azure.ARO.HCP.Frontend": {"svc.frontend", "frontendKeyVault"},
+			"Microsoft.Azure.ARO.HCP.Backend":  {"svc.backend"},
+		},
+		Shared: []string{"svc.frontend.image"},
+		Reads: map[string][]string{
+			"Microsoft.Azure.ARO.HCP.Backend": {"frontendKeyVault.name"},
+		},
+	}
+
+	for _, testCase := range []struct {
+		name, serviceGroup, path string
+		expected                 []string
+	}{
+		{name: "unowned", serviceGroup: "Microsoft.Azure.ARO.HCP.Backend", path: "regionRG"},
+		{name: "owned", serviceGroup: "Microsoft.Azure.ARO.HCP.Frontend", path: "svc.frontend.replicas"},
+		{name: "shared", serviceGroup: "Microsoft.Azure.ARO.HCP.Backend", path: "svc.frontend.image.digest"},
+		{name: "explicitly read", serviceGroup: "Microsoft.Azure.ARO.HCP.Backend", path: "frontendKeyVault.name"},
+		{
+			name: "owned by another", serviceGroup: "Microsoft.Azure.ARO.HCP.Backend", path: "svc.frontend.replicas",
+			expected: []string{"step: Microsoft.Azure.ARO.HCP.Backend references svc.frontend.replicas, but svc.frontend is owned by Microsoft.Azure.ARO.HCP.Frontend"},
+		},
+		{
+			name: "parent of sub-trees owned by others", serviceGroup: "Microsoft.Azure.ARO.HCP.Other", path: "svc",
+			expected: []string{
+				"step: Microsoft.Azure.ARO.HCP.Other references svc, but svc.backend is owned by Microsoft.Azure.ARO.HCP.Backend",
+				"step: Microsoft.Azure.ARO.HCP.Other references svc, but svc.frontend is owned by Microsoft.Azure.ARO.HCP.Frontend",
+			},
+		},
+		{
+			name: "parent of a shared sub-tree", serviceGroup: "Microsoft.Azure.ARO.HCP.Backend", path: "frontendKeyVault",
+			expected: []string{"step: Microsoft.Azure.ARO.HCP.Backend references frontendKeyVault, but frontendKeyVault is owned by Microsoft.Azure.ARO.HCP.Frontend"},
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			var violations []string
+			for _, violation := range scopes.Check(testCase.serviceGroup, testCase.path, "step") {
+				violations = append(violations, violation.String())
+			}
+			if diff := cmp.Diff(testCase.expected, violations); diff != "" {
+				t.Errorf("incorrect violations (-want, +got): %v", diff)
+			}
+		})
+	}
+}
+
+func TestValidateConfigScopes(t *testing.T) {
+	input := `services:
+- serviceGroup: Microsoft.Azure.ARO.HCP.Frontend
+  pipelinePath: foo
+  purpose: stuff
+- serviceGroup: Microsoft.Azure.ARO.HCP.Backend
+  pipelinePath: foo
+  purpose: stuff
+configScopes:
+  owners:
+    Microsoft.Azure.ARO.HCP.Frontend:
+    - svc.frontend
+    - svc.frontend.image
+    Microsoft.Azure.ARO.HCP.Backend:
+    - svc
+    Microsoft.Azure.ARO.HCP.Missing:
+    - missing
+  shared:
+  - svc..image
+  reads:
+    Microsoft.Azure.ARO.HCP.Backend:
+    - svc.frontend.image
+`
+	var topo Topology
+	if err := yaml.Unmarshal([]byte(input), &topo); err != nil {
+		t.Fatalf("Failed to unmarshal topology: %v", err)
+	}
+	err := topo.Validate()
+	if err == nil {
+		t.Fatalf("expected an error validating configuration scopes")
+	}
+	for _, expected := range []string{
+		"configScopes.owners: service group Microsoft.Azure.ARO.HCP.Missing was not found in the dependency tree",
+		`configScopes.shared: path "svc..image" must be in dot notation format`,
+		"configScopes.owners: svc owned by Microsoft.Azure.ARO.HCP.Backend overlaps svc.frontend owned by Microsoft.Azure.ARO.HCP.Frontend",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error to contain %q, got %v", expected, err)
+		}
+	}
+	if unexpected := "svc.frontend owned by Microsoft.Azure.ARO.HCP.Frontend overlaps svc.frontend.image"; strings.Contains(err.Error(), unexpected) {
+		t.Errorf("expected nested sub-trees with one owner not to overlap, got %v", err)
+	}
+}
@@ -16,6 +16,9 @@ type Topology struct {
 
 	// Entrypoints selects specific sub-trees that are deployed together.
 	Entrypoints []Entrypoint `json:"entrypoints,omitempty"`
+
+	// ConfigScopes optionally declares which service groups own which sub-trees of the service configuration.
+	ConfigScopes *ConfigScopes `json:"configScopes,omitempty"`
 }
 
 // Service describes an individual service in the tree.
@@ -78,6 +81,9 @@ func (v *validator) validate(t *Topology) error {
 	if v.duplicates.Len() > 0 {
 		messages = append(messages, fmt.Sprintf("the following pipelines had duplicate entries: %v", sets.List(v.duplicates)))
 	}
+	if t.ConfigScopes != nil {
+		messages = append(messages, t.ConfigScopes.validate(v.seen)...)
+	}
 	for _, entrypoint := range t.Entrypoints {
 		if entrypoint.Identifier == "" {
 			messages = append(messages, "entrypoint identifier cannot be empty")
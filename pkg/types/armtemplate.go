@@ -0,0 +1,423 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Azure/ARO-Tools/pkg/config"
+	types2 "github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+// ARMTemplate holds the parameters and outputs of a compiled ARM template.
+type ARMTemplate struct {
+	Parameters map[string]ARMTemplateParameter `json:"parameters,omitempty"`
+	Outputs    map[string]ARMTemplateOutput    `json:"outputs,omitempty"`
+}
+
+// ARMTemplateParameter declares a parameter of an ARM template. Parameters of user-defined types have no Type.
+type ARMTemplateParameter struct {
+	Type         string          `json:"type,omitempty"`
+	DefaultValue json.RawMessage `json:"defaultValue,omitempty"`
+	Nullable     bool            `json:"nullable,omitempty"`
+}
+
+// Required determines if a value must be supplied for the parameter.
+func (p ARMTemplateParameter) Required() bool {
+	return p.DefaultValue == nil && !p.Nullable
+}
+
+// ARMTemplateOutput declares an output of an ARM template.
+type ARMTemplateOutput struct {
+	Type string `json:"type,omitempty"`
+}
+
+// LoadARMTemplate reads the compiled ARM template for a step's template. A .bicep template is compiled to the .json
+// file next to it, which must already exist, as no compiler is run; any other path is read as is.
+func LoadARMTemplate(path string) (*ARMTemplate, error) {
+	if filepath.Ext(path) == ".bicep" {
+		path = strings.TrimSuffix(path, ".bicep") + ".json"
+	}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("compiled template %s not found, build it with 'az bicep build'", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read compiled template: %w", err)
+	}
+	var template ARMTemplate
+	if err := json.Unmarshal(raw, &template); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal compiled template %s: %w", path, err)
+	}
+	return &template, nil
+}
+
+// OutputNames lists the outputs of the template, sorted.
+func (t *ARMTemplate) OutputNames() []string {
+	names := make([]string, 0, len(t.Outputs))
+	for name := range t.Outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ARMTemplateFailure records that a step passes values to an ARM template which don't match it, or that a step
+// consumes an output the template doesn't have.
+type ARMTemplateFailure struct {
+	// Field locates the problem in the pipeline or in a parameters file.
+	Field   string
+	Message string
+}
+
+func (f ARMTemplateFailure) String() string {
+	return fmt.Sprintf("%s: %s", f.Field, f.Message)
+}
+
+// ARMTemplateError records every mismatch between the steps of a pipeline and the ARM templates they deploy.
+type ARMTemplateError struct {
+	Failures []ARMTemplateFailure
+}
+
+func (e *ARMTemplateError) Error() string {
+	var messages []string
+	for _, failure := range e.Failures {
+		messages = append(messages, failure.String())
+	}
+	return fmt.Sprintf("steps do not match their ARM templates:\n%s", strings.Join(messages, "\n"))
+}
+
+// armStep holds what is needed to validate an ARM or ARM stack step.
+type armStep struct {
+	// resourceGroup and step index the step in the pipeline
+	resourceGroup, step int
+	field               string
+	template            string
+	parameters          string
+	variables           []Variable
+}
+
+func (p *Pipeline) armSteps() map[StepDependency]armStep {
+	steps := map[StepDependency]armStep{}
+	for i, rg := range p.ResourceGroups {
+		for j, step := range rg.Steps {
+			id := StepDependency{ResourceGroup: rg.Name, Step: step.StepName()}
+			field := fmt.Sprintf("resourceGroups[%d].steps[%d]", i, j)
+			switch s := step.(type) {
+			case *ARMStep:
+				steps[id] = armStep{resourceGroup: i, step: j, field: field, template: s.Template, parameters: s.Parameters, variables: s.Variables}
+			case *ARMStackStep:
+				steps[id] = armStep{resourceGroup: i, step: j, field: field, template: s.Template, parameters: s.Parameters, variables: s.Variables}
+			}
+		}
+	}
+	return steps
+}
+
+// validateARMTemplates checks the values every ARM and ARM stack step passes to its template, through the .bicepparam
+// or JSON parameters file and variables, against the compiled template: required parameters must be supplied,
+// parameters must exist, literals and configuration values must have the parameter's type and inputs from other ARM
+// steps must name outputs of the right type. Inputs consumed by any step from an ARM step must name one of its outputs.
+// Files are found relative to the directory holding the pipeline.
+func validateARMTemplates(pipelineDir string, pipeline *Pipeline, cfg types2.Configuration) error {
+	validationErr := &ARMTemplateError{}
+	fail := func(field, format string, args ...any) {
+		validationErr.Failures = append(validationErr.Failures, ARMTemplateFailure{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	steps := pipeline.armSteps()
+	templates := map[StepDependency]*ARMTemplate{}
+	for _, id := range sortedStepDependencies(steps) {
+		step := steps[id]
+		if step.template == "" {
+			continue
+		}
+		template, err := LoadARMTemplate(filepath.Join(pipelineDir, step.template))
+		if err != nil {
+			fail(step.field+".template", "%v", err)
+			continue
+		}
+		templates[id] = template
+	}
+
+	for _, id := range sortedStepDependencies(steps) {
+		step, template := steps[id], templates[id]
+		if template == nil {
+			continue
+		}
+
+		supplied := map[string]bool{}
+		// when the parameters file can't be read, what it supplies is unknown
+		parametersUnknown := false
+		if step.parameters != "" {
+			parameters, err := parseARMParameters(filepath.Join(pipelineDir, step.parameters), cfg)
+			if err != nil {
+				fail(step.field+".parameters", "%v", err)
+				parametersUnknown = true
+			}
+			for _, param := range parameters {
+				supplied[param.name] = true
+				checkARMParameter(template, param.name, param.value, param.known, param.source, fail)
+			}
+		}
+
+		for k, variable := range step.variables {
+			supplied[variable.Name] = true
+			field := fmt.Sprintf("%s.variables[%d]", step.field, k)
+			switch {
+			case variable.Input != nil:
+				if _, ok := template.Parameters[variable.Name]; !ok {
+					fail(field, "parameter %s is not declared by the template", variable.Name)
+					continue
+				}
+				producer, ok := templates[variable.Input.StepDependency]
+				if !ok {
+					continue
+				}
+				output, ok := producer.Outputs[variable.Input.Name]
+				if ok && !armTypesMatch(template.Parameters[variable.Name].Type, output.Type) {
+					fail(field, "parameter %s has type %s, but output %s of %s/%s has type %s", variable.Name, template.Parameters[variable.Name].Type, variable.Input.Name, variable.Input.ResourceGroup, variable.Input.Step, output.Type)
+				}
+			case variable.ConfigRef != "":
+				value, err := cfg.GetByPath(variable.ConfigRef)
+				checkARMParameter(template, variable.Name, value, err == nil, field, fail)
+			default:
+				checkARMParameter(template, variable.Name, variable.Value.Value, true, field, fail)
+			}
+		}
+
+		if parametersUnknown {
+			continue
+		}
+		var required []string
+		for name, parameter := range template.Parameters {
+			if parameter.Required() && !supplied[name] {
+				required = append(required, name)
+			}
+		}
+		sort.Strings(required)
+		for _, name := range required {
+			fail(step.field, "required parameter %s of template %s is not supplied", name, step.template)
+		}
+	}
+
+	// every input read from an ARM step must be one of its outputs
+	for i, rg := range pipeline.ResourceGroups {
+		for j, step := range rg.Steps {
+			raw, err := json.Marshal(step)
+			if err != nil {
+				return fmt.Errorf("failed to marshal step: %w", err)
+			}
+			var document any
+			if err := json.Unmarshal(raw, &document); err != nil {
+				return fmt.Errorf("failed to unmarshal step: %w", err)
+			}
+			var inputs []inputReference
+			collectInputs(document, fmt.Sprintf("resourceGroups[%d].steps[%d]", i, j), &inputs)
+			for _, input := range inputs {
+				producer, ok := templates[input.StepDependency]
+				if !ok {
+					continue
+				}
+				if _, ok := producer.Outputs[input.Name]; !ok {
+					fail(input.field, "step %s/%s has no output %s, only %s", input.ResourceGroup, input.Step, input.Name, strings.Join(producer.OutputNames(), ", "))
+				}
+			}
+		}
+	}
+
+	if len(validationErr.Failures) > 0 {
+		return validationErr
+	}
+	return nil
+}
+
+// armParameter is a value supplied for a template parameter by a parameter file.
+type armParameter struct {
+	name  string
+	value any
+	// known is unset when the value is only known at deployment, like a Key Vault reference
+	known bool
+	// source locates the value in the parameter file
+	source string
+}
+
+// parseARMParameters preprocesses and parses a .bicepparam or JSON parameter file, returning the values it supplies.
+func parseARMParameters(path string, cfg types2.Configuration) ([]armParameter, error) {
+	if isBicepParamFile(path) {
+		parsed, err := parseParameterFile(path, cfg)
+		if err != nil {
+			return nil, err
+		}
+		var parameters []armParameter
+		for _, param := range parsed.Params {
+			parameters = append(parameters, armParameter{
+				name:   param.Name,
+				value:  param.Value,
+				known:  true,
+				source: fmt.Sprintf("%s:%d:%d", path, param.Position.Line, param.Position.Column),
+			})
+		}
+		return parameters, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	preprocessed, err := config.PreprocessFileContent(path, raw, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to preprocess parameters: %w", err)
+	}
+	var file struct {
+		Parameters map[string]struct {
+			Value     any `json:"value"`
+			Reference any `json:"reference"`
+		} `json:"parameters"`
+	}
+	if err := json.Unmarshal(preprocessed, &file); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal parameter file %s: %w", path, err)
+	}
+	names := make([]string, 0, len(file.Parameters))
+	for name := range file.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var parameters []armParameter
+	for _, name := range names {
+		parameters = append(parameters, armParameter{
+			name:   name,
+			value:  file.Parameters[name].Value,
+			known:  file.Parameters[name].Reference == nil,
+			source: fmt.Sprintf("%s: parameters.%s", path, name),
+		})
+	}
+	return parameters, nil
+}
+
+// checkARMParameter checks that a value supplied for a parameter is declared by the template and, if the value is
+// known, that it has the parameter's type.
+func checkARMParameter(template *ARMTemplate, name string, value any, known bool, source string, fail func(field, format string, args ...any)) {
+	parameter, ok := template.Parameters[name]
+	if !ok {
+		fail(source, "parameter %s is not declared by the template", name)
+		return
+	}
+	if !known {
+		return
+	}
+	if value == nil {
+		if !parameter.Nullable && parameter.Type != "" {
+			fail(source, "parameter %s of type %s is not nullable, but null is supplied", name, parameter.Type)
+		}
+		return
+	}
+	if literal := literalARMType(value); !armTypesMatch(parameter.Type, literal) {
+		fail(source, "parameter %s has type %s, but the value supplied has type %s", name, parameter.Type, literal)
+	}
+}
+
+// literalARMType returns the ARM type of a literal value.
+func literalARMType(value any) string {
+	switch typed := value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case int, int32, int64, uint, uint32, uint64:
+		return "int"
+	case float64:
+		if typed == math.Trunc(typed) {
+			return "int"
+		}
+		return "number"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// armTypesMatch determines if a value of one ARM type may be passed for another. Types are case-insensitive, secure
+// types hold the same values as the others and an unknown type, like a user-defined one, matches anything.
+func armTypesMatch(parameterType, valueType string) bool {
+	normalize := func(t string) string {
+		return strings.TrimPrefix(strings.ToLower(t), "secure")
+	}
+	if parameterType == "" || valueType == "" {
+		return true
+	}
+	return normalize(parameterType) == normalize(valueType)
+}
+
+// inputReference is an input consumed by a step, along with where it is in the pipeline.
+type inputReference struct {
+	Input
+	field string
+}
+
+// collectInputs finds every input in a marshalled step: objects naming a resource group, a step and an output.
+func collectInputs(value any, field string, into *[]inputReference) {
+	switch typed := value.(type) {
+	case map[string]any:
+		resourceGroup, _ := typed["resourceGroup"].(string)
+		step, _ := typed["step"].(string)
+		name, _ := typed["name"].(string)
+		if resourceGroup != "" && step != "" && name != "" {
+			*into = append(*into, inputReference{
+				Input: Input{StepDependency: StepDependency{ResourceGroup: resourceGroup, Step: step}, Name: name},
+				field: field,
+			})
+			return
+		}
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			collectInputs(typed[key], field+"."+key, into)
+		}
+	case []any:
+		for i, item := range typed {
+			collectInputs(item, fmt.Sprintf("%s[%d]", field, i), into)
+		}
+	}
+}
+
+func sortedStepDependencies(steps map[StepDependency]armStep) []StepDependency {
+	ids := make([]StepDependency, 0, len(steps))
+	for id := range steps {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := steps[ids[i]], steps[ids[j]]
+		if a.resourceGroup != b.resourceGroup {
+			return a.resourceGroup < b.resourceGroup
+		}
+		return a.step < b.step
+	})
+	return ids
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 Microsoft Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Azure/ARO-Tools/pkg/bicepparam"
+	"github.com/Azure/ARO-Tools/pkg/config"
+	types2 "github.com/Azure/ARO-Tools/pkg/config/types"
+)
+
+// ParameterFileFailure records that a .bicepparam file deployed by a pipeline is invalid.
+type ParameterFileFailure struct {
+	// Field locates the first reference to the file in the pipeline, like resourceGroups[0].steps[1].parameters.
+	Field string
+	Err   error
+}
+
+func (f ParameterFileFailure) String() string {
+	return fmt.Sprintf("%s: %v", f.Field, f.Err)
+}
+
+// ParameterFileError records every invalid .bicepparam file deployed by a pipeline.
+type ParameterFileError struct {
+	Failures []ParameterFileFailure
+}
+
+func (e *ParameterFileError) Error() string {
+	var messages []string
+	for _, failure := range e.Failures {
+		messages = append(messages, failure.String())
+	}
+	return fmt.Sprintf("invalid parameter files:\n%s", strings.Join(messages, "\n"))
+}
+
+// fileReference is a reference from a pipeline field to a file, relative to the directory holding the pipeline.
+type fileReference struct {
+	path, field string
+}
+
+// parameterFiles lists the parameter files deployed by the pipeline, in the order they appear. ARM steps may take
+// .bicepparam files or JSON parameter files, so callers check the extension before parsing.
+func (p *Pipeline) parameterFiles() []fileReference {
+	var files []fileReference
+	for i, rg := range p.ResourceGroups {
+		if rg.SubscriptionProvisioning != nil && rg.SubscriptionProvisioning.RoleAssignmentParameters != "" {
+			files = append(files, fileReference{
+				path:  rg.SubscriptionProvisioning.RoleAssignmentParameters,
+				field: fmt.Sprintf("resourceGroups[%d].subscriptionProvisioning.roleAssignment", i),
+			})
+		}
+		for j, step := range rg.Steps {
+			var parameters string
+			switch s := step.(type) {
+			case *ARMStep:
+				parameters = s.Parameters
+			case *ARMStackStep:
+				parameters = s.Parameters
+			}
+			if parameters != "" {
+				files = append(files, fileReference{path: parameters, field: fmt.Sprintf("resourceGroups[%d].steps[%d].parameters", i, j)})
+			}
+		}
+	}
+	return files
+}
+
+// validateParameterFiles preprocesses and parses every .bicepparam file the pipeline deploys, checking that the
+// template each file is for exists. JSON parameter files are skipped. Files are found relative to the directory holding
+// the pipeline, and the template relative to the directory holding the file, as the Bicep compiler does.
+func validateParameterFiles(pipelineDir string, pipeline *Pipeline, cfg types2.Configuration) error {
+	validationErr := &ParameterFileError{}
+	checked := map[string]bool{}
+	for _, file := range pipeline.parameterFiles() {
+		if !isBicepParamFile(file.path) {
+			continue
+		}
+		path := filepath.Join(pipelineDir, file.path)
+		if checked[path] {
+			continue
+		}
+		checked[path] = true
+		if err := validateParameterFile(path, cfg); err != nil {
+			validationErr.Failures = append(validationErr.Failures, ParameterFileFailure{Field: file.field, Err: err})
+		}
+	}
+	if len(validationErr.Failures) > 0 {
+		return validationErr
+	}
+	return nil
+}
+
+func validateParameterFile(path string, cfg types2.Configuration) error {
+	parsed, err := parseParameterFile(path, cfg)
+	if err != nil {
+		return err
+	}
+	return parsed.CheckUsing(path, filepath.Dir(path))
+}
+
+// isBicepParamFile determines if a parameter file is a .bicepparam file, rather than a JSON parameter file.
+func isBicepParamFile(path string) bool {
+	return filepath.Ext(path) == ".bicepparam"
+}
+
+// parseParameterFile preprocesses and parses a .bicepparam file.
+func parseParameterFile(path string, cfg types2.Configuration) (*bicepparam.File, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	preprocessed, err := config.PreprocessFileContent(path, raw, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to preprocess parameters: %w", err)
+	}
+	return bicepparam.Parse(path, preprocessed)
+}
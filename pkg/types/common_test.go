@@ -306,3 +306,19 @@ func TestIsWellFormedOverInputs(t *testing.T) {
 		})
 	}
 }
+
+func TestVariableString(t *testing.T) {
+	for _, testCase := range []struct {
+		variable Variable
+		expected string
+	}{
+		{variable: Variable{Name: "REGION", Value: Value{Value: "uksouth"}}, expected: "$REGION=uksouth"},
+		{variable: Variable{Name: "DB_PASSWORD", Value: Value{Value: "hunter2"}}, expected: "$DB_PASSWORD=<redacted>"},
+		{variable: Variable{Name: "API_KEY", Value: Value{Value: "abc"}}, expected: "$API_KEY=<redacted>"},
+		{variable: Variable{Name: "DB_PASSWORD", Value: Value{ConfigRef: "db.password"}}, expected: "$DB_PASSWORD={{ db.password }}"},
+	} {
+		if diff := cmp.Diff(testCase.expected, testCase.variable.String()); diff != "" {
+			t.Errorf("%s: unexpected string (-want, +got): %s", testCase.variable.Name, diff)
+		}
+	}
+}
@@ -15,8 +15,11 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 
@@ -24,6 +27,7 @@ import (
 
 	"github.com/Azure/ARO-Tools/pkg/config"
 	types2 "github.com/Azure/ARO-Tools/pkg/config/types"
+	"github.com/Azure/ARO-Tools/pkg/topology"
 )
 
 type Pipeline struct {
@@ -44,6 +48,42 @@ type BuildStep struct {
 	Args []string `json:"args"`
 }
 
+// PipelineOption configures optional checks made when loading a pipeline.
+type PipelineOption func(*pipelineOptions)
+
+type pipelineOptions struct {
+	configScopes         *topology.ConfigScopes
+	validateBicepParams  bool
+	validateARMTemplates bool
+}
+
+// WithConfigScopes makes loading a pipeline fail if its configRef fields or template expressions reference
+// configuration outside of the scopes allowed for its service group.
+func WithConfigScopes(scopes *topology.ConfigScopes) PipelineOption {
+	return func(o *pipelineOptions) {
+		o.configScopes = scopes
+	}
+}
+
+// WithBicepParamValidation makes loading a pipeline fail if a .bicepparam file it deploys does not parse once
+// preprocessed, declares a param twice or is for a template that doesn't exist. Pipelines loaded from bytes find
+// parameter files relative to the working directory.
+func WithBicepParamValidation() PipelineOption {
+	return func(o *pipelineOptions) {
+		o.validateBicepParams = true
+	}
+}
+
+// WithARMTemplateValidation makes loading a pipeline fail if an ARM or ARM stack step passes parameters that its
+// compiled template doesn't declare or that have the wrong type, or leaves out required ones, or if a step consumes an
+// output that an ARM step's template doesn't produce. Templates are read from the .json file next to each .bicep file,
+// so they must be compiled first. Pipelines loaded from bytes find files relative to the working directory.
+func WithARMTemplateValidation() PipelineOption {
+	return func(o *pipelineOptions) {
+		o.validateARMTemplates = true
+	}
+}
+
 // NewPipelineFromFile prepocesses and creates a new Pipeline instance from a file.
 //
 // Parameters:
@@ -54,17 +94,27 @@ type BuildStep struct {
 //   - A pointer to a new Pipeline instance if successful.
 //   - An error if there was a problem preprocessing the file, validating the schema,
 //     unmarshaling the pipeline, or validating the pipeline instance.
-func NewPipelineFromFile(pipelineFilePath string, cfg types2.Configuration) (*Pipeline, error) {
+func NewPipelineFromFile(pipelineFilePath string, cfg types2.Configuration, opts ...PipelineOption) (*Pipeline, error) {
 	content, err := os.ReadFile(pipelineFilePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file %s: %w", pipelineFilePath, err)
 	}
 
-	return NewPipelineFromBytes(content, cfg)
+	return newPipelineFromBytes(pipelineFilePath, content, cfg, opts...)
 }
 
-func NewPipelineFromBytes(pipelineBytes []byte, cfg types2.Configuration) (*Pipeline, error) {
-	bytes, err := config.PreprocessContent(pipelineBytes, cfg)
+func NewPipelineFromBytes(pipelineBytes []byte, cfg types2.Configuration, opts ...PipelineOption) (*Pipeline, error) {
+	return newPipelineFromBytes("", pipelineBytes, cfg, opts...)
+}
+
+// newPipelineFromBytes creates a pipeline from raw bytes read from a file, if known, so that errors can name it.
+func newPipelineFromBytes(pipelineFilePath string, pipelineBytes []byte, cfg types2.Configuration, opts ...PipelineOption) (*Pipeline, error) {
+	options := pipelineOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	bytes, err := config.PreprocessFileContent(pipelineFilePath, pipelineBytes, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to preprocess pipeline file: %w", err)
 	}
@@ -82,9 +132,128 @@ func NewPipelineFromBytes(pipelineBytes []byte, cfg types2.Configuration) (*Pipe
 		return nil, fmt.Errorf("pipeline file failed validation: %w", err)
 	}
 
+	if options.configScopes != nil {
+		if err := checkConfigScopes(options.configScopes, pipelineFilePath, pipelineBytes, &pipeline); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.validateBicepParams {
+		if err := validateParameterFiles(filepath.Dir(pipelineFilePath), &pipeline, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.validateARMTemplates {
+		if err := validateARMTemplates(filepath.Dir(pipelineFilePath), &pipeline, cfg); err != nil {
+			return nil, err
+		}
+	}
+
 	return &pipeline, nil
 }
 
+// checkConfigScopes ensures that the template expressions in the raw pipeline and the configuration references in the
+// loaded pipeline only read configuration that the pipeline's service group may.
+func checkConfigScopes(scopes *topology.ConfigScopes, pipelineFilePath string, raw []byte, pipeline *Pipeline) error {
+	templateRefs, err := config.ExtractTemplateReferences(raw)
+	if err != nil {
+		return fmt.Errorf("failed to extract template references: %w", err)
+	}
+	var violations []topology.ScopeViolation
+	for _, ref := range templateRefs {
+		source := fmt.Sprintf("%d:%d", ref.Line, ref.Column)
+		if pipelineFilePath != "" {
+			source = pipelineFilePath + ":" + source
+		}
+		violations = append(violations, scopes.Check(pipeline.ServiceGroup, ref.Path, source)...)
+	}
+	refs, err := pipeline.ConfigReferences()
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		violations = append(violations, scopes.Check(pipeline.ServiceGroup, ref.Path, ref.Field)...)
+	}
+	if len(violations) > 0 {
+		return &topology.ScopeError{Violations: violations}
+	}
+	return nil
+}
+
+// ConfigReference is a reference from a pipeline to a configuration value through a configRef or providerConfigRef.
+type ConfigReference struct {
+	// Path is the referenced configuration path.
+	Path string
+	// Field locates the reference in the pipeline, like resourceGroups[0].steps[1].variables[0].configRef.
+	Field string
+}
+
+// ConfigReferences lists every configRef and providerConfigRef in the pipeline. Resource groups, steps and other lists
+// are visited in order, while the fields of each object are visited alphabetically, so the result is stable.
+func (p *Pipeline) ConfigReferences() ([]ConfigReference, error) {
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal pipeline: %w", err)
+	}
+	var document any
+	if err := json.Unmarshal(raw, &document); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal pipeline: %w", err)
+	}
+	var refs []ConfigReference
+	collectConfigReferences(document, "", &refs)
+	return refs, nil
+}
+
+func collectConfigReferences(value any, field string, into *[]ConfigReference) {
+	switch typed := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := key
+			if field != "" {
+				child = field + "." + key
+			}
+			if path, ok := typed[key].(string); ok && (key == "configRef" || key == "providerConfigRef") && path != "" {
+				*into = append(*into, ConfigReference{Path: path, Field: child})
+				continue
+			}
+			collectConfigReferences(typed[key], child, into)
+		}
+	case []any:
+		for i, item := range typed {
+			collectConfigReferences(item, fmt.Sprintf("%s[%d]", field, i), into)
+		}
+	}
+}
+
+// TemplatedFiles lists the files that the pipeline deploys which are preprocessed with the configuration before use:
+// .bicepparam files, Helm values files and namespace files. Paths are relative to the directory holding the pipeline,
+// sorted and unique.
+func (p *Pipeline) TemplatedFiles() []string {
+	files := sets.New[string]()
+	for _, file := range p.parameterFiles() {
+		files.Insert(file.path)
+	}
+	for _, rg := range p.ResourceGroups {
+		for _, step := range rg.Steps {
+			helm, ok := step.(*HelmStep)
+			if !ok {
+				continue
+			}
+			if helm.ValuesFile != "" {
+				files.Insert(helm.ValuesFile)
+			}
+			files.Insert(helm.NamespaceFiles...)
+		}
+	}
+	return sets.List(files)
+}
+
 // Validate checks the integrity of the pipeline and its resource groups.
 // It ensures that there are no duplicate step names, that all dependencies exist,
 // and that each resource group is valid.
@@ -15,6 +15,9 @@
 package types
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -23,6 +26,7 @@ import (
 	"github.com/Azure/ARO-Tools/internal/testutil"
 	"github.com/Azure/ARO-Tools/pkg/config"
 	"github.com/Azure/ARO-Tools/pkg/config/ev2config"
+	"github.com/Azure/ARO-Tools/pkg/topology"
 )
 
 func TestNewPipelineFromFile(t *testing.T) {
@@ -234,3 +238,190 @@ resourceGroups:
 		})
 	}
 }
+
+func TestNewPipelineFromFileTemplateError(t *testing.T) {
+	pipelinePath := filepath.Join(t.TempDir(), "pipeline.yaml")
+	require.NoError(t, os.WriteFile(pipelinePath, []byte(`serviceGroup: Microsoft.Azure.ARO.Test
+rolloutName: Test Rollout
+resourceGroups:
+- name: {{ .regionRG }}
+  subscription: {{ .svc.subscripton }}
+`), 0644))
+
+	_, err := NewPipelineFromFile(pipelinePath, map[string]any{
+		"regionRG": "hcp-underlay",
+		"svc":      map[string]any{"subscription": "hcp", "subscriptionKey": "key"},
+	})
+	var templateErr *config.TemplateError
+	require.ErrorAs(t, err, &templateErr)
+	require.Equal(t, pipelinePath, templateErr.File)
+	require.Equal(t, 5, templateErr.Line)
+	require.Equal(t, "svc.subscripton", templateErr.MissingKey)
+	require.Equal(t, []string{"svc.subscription"}, templateErr.Suggestions)
+}
+
+func TestNewPipelineFromBytesConfigScopes(t *testing.T) {
+	pipeline := []byte(`$schema: pipeline.schema.v1
+serviceGroup: Microsoft.Azure.ARO.Test.Backend
+rolloutName: Test Rollout
+resourceGroups:
+- name: test
+  resourceGroup: {{ .backend.rg }}
+  subscription: {{ .frontend.subscription }}
+  steps:
+  - name: deploy
+    action: Shell
+    command: make deploy
+    variables:
+    - name: IMAGE
+      configRef: frontend.image.digest
+    - name: VAULT
+      configRef: frontend.keyVault
+`)
+	cfg := map[string]any{
+		"backend":  map[string]any{"rg": "backend-rg"},
+		"frontend": map[string]any{"subscription": "sub", "keyVault": "kv", "image": map[string]any{"digest": "sha256:abc"}},
+	}
+	scopes := &topology.ConfigScopes{
+		Owners: map[string][]string{
+			"Microsoft.Azure.ARO.Test.Frontend": {"frontend"},
+			"Microsoft.Azure.ARO.Test.Backend":  {"backend"},
+		},
+		Shared: []string{"frontend.image"},
+	}
+
+	_, err := NewPipelineFromBytes(pipeline, cfg, WithConfigScopes(scopes))
+	var scopeErr *topology.ScopeError
+	require.ErrorAs(t, err, &scopeErr)
+	var violations []string
+	for _, violation := range scopeErr.Violations {
+		violations = append(violations, violation.String())
+	}
+	require.Equal(t, []string{
+		"7:20: Microsoft.Azure.ARO.Test.Backend references frontend.subscription, but frontend is owned by Microsoft.Azure.ARO.Test.Frontend",
+		"resourceGroups[0].steps[0].variables[1].configRef: Microsoft.Azure.ARO.Test.Backend references frontend.keyVault, but frontend is owned by Microsoft.Azure.ARO.Test.Frontend",
+	}, violations)
+
+	scopes.Reads = map[string][]string{"Microsoft.Azure.ARO.Test.Backend": {"frontend.subscription", "frontend.keyVault"}}
+	_, err = NewPipelineFromBytes(pipeline, cfg, WithConfigScopes(scopes))
+	require.NoError(t, err)
+}
+
+func TestNewPipelineFromFileBicepParamValidation(t *testing.T) {
+	dir := t.TempDir()
+	pipelinePath := filepath.Join(dir, "pipeline.yaml")
+	require.NoError(t, os.WriteFile(pipelinePath, []byte(`$schema: pipeline.schema.v1
+serviceGroup: Microsoft.Azure.ARO.Test
+rolloutName: Test Rollout
+resourceGroups:
+- name: regional
+  resourceGroup: hcp-underlay
+  subscription: hcp
+  subscriptionProvisioning:
+    displayName:
+      value: hcp
+    roleAssignment: configurations/roles.bicepparam
+  steps:
+  - name: region
+    action: ARM
+    template: templates/region.bicep
+    parameters: configurations/region.bicepparam
+    deploymentLevel: ResourceGroup
+  - name: region-stack
+    action: ARMStack
+    template: templates/region.bicep
+    parameters: configurations/region.bicepparam
+    deploymentLevel: ResourceGroup
+    actionOnUnmanage: delete
+  - name: region-json
+    action: ARM
+    template: templates/region.bicep
+    parameters: configurations/region.parameters.json
+    deploymentLevel: ResourceGroup
+`), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "configurations"), 0755))
+	// JSON parameter files are not .bicepparam files, so they must not be parsed as one
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "configurations", "region.parameters.json"), []byte(`{"parameters": {"azCount": {"value": 3}}}`), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "templates"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "templates", "region.bicep"), []byte("param azCount int\n"), 0644))
+	writeParams := func(region, roles string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "configurations", "region.bicepparam"), []byte(region), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "configurations", "roles.bicepparam"), []byte(roles), 0644))
+	}
+
+	writeParams("using '../templates/region.bicep'\nparam azCount = {{ .availabilityZoneCount }}\n", "using '../templates/region.bicep'\n")
+	_, err := NewPipelineFromFile(pipelinePath, map[string]any{"availabilityZoneCount": 3}, WithBicepParamValidation())
+	require.NoError(t, err)
+
+	// the empty substitution is only caught once the file is preprocessed
+	_, err = NewPipelineFromFile(pipelinePath, map[string]any{"availabilityZoneCount": ""}, WithBicepParamValidation())
+	var paramErr *ParameterFileError
+	require.ErrorAs(t, err, &paramErr)
+	require.Len(t, paramErr.Failures, 1)
+	require.Equal(t, "resourceGroups[0].steps[0].parameters", paramErr.Failures[0].Field)
+	require.ErrorContains(t, paramErr.Failures[0].Err, "region.bicepparam:2:17: expected a value for param azCount, found a new line")
+
+	writeParams("using '../templates/region.bicep'\nparam azCount = 3\nparam azCount = 1\n", "using '../templates/roles.bicep'\n")
+	_, err = NewPipelineFromFile(pipelinePath, nil, WithBicepParamValidation())
+	require.ErrorAs(t, err, &paramErr)
+	var failures []string
+	for _, failure := range paramErr.Failures {
+		failures = append(failures, failure.String())
+	}
+	require.Equal(t, []string{
+		"resourceGroups[0].subscriptionProvisioning.roleAssignment: " + filepath.Join(dir, "configurations", "roles.bicepparam") + ":1:1: using target ../templates/roles.bicep does not exist: stat " + filepath.Join(dir, "templates", "roles.bicep") + ": no such file or directory",
+		"resourceGroups[0].steps[0].parameters: " + filepath.Join(dir, "configurations", "region.bicepparam") + ":3:7: param azCount is already declared at 2:7",
+	}, failures)
+
+	_, err = NewPipelineFromFile(pipelinePath, nil)
+	require.NoError(t, err, "parameter files are only validated when asked to")
+}
+
+func TestNewPipelineFromFileARMTemplateValidation(t *testing.T) {
+	cfg := map[string]any{
+		"regionRG":              "hcp-underlay",
+		"availabilityZoneCount": 3,
+		"admin":                 map[string]any{"password": 42},
+	}
+	_, err := NewPipelineFromFile("testdata/arm/pipeline.yaml", cfg, WithARMTemplateValidation())
+	var templateErr *ARMTemplateError
+	require.ErrorAs(t, err, &templateErr)
+	var failures []string
+	for _, failure := range templateErr.Failures {
+		failures = append(failures, failure.String())
+	}
+	require.Equal(t, []string{
+		"resourceGroups[0].steps[4].template: compiled template testdata/arm/templates/missing.json not found, build it with 'az bicep build'",
+		"resourceGroups[0].steps[0].variables[0]: parameter adminPassword has type securestring, but the value supplied has type int",
+		"resourceGroups[0].steps[1].variables[1]: parameter zones has type string, but output zones of regional/region has type array",
+		"testdata/arm/configurations/broken.bicepparam:4:7: parameter azCount has type int, but the value supplied has type string",
+		"testdata/arm/configurations/broken.bicepparam:6:7: parameter location is not declared by the template",
+		"resourceGroups[0].steps[3].variables[1]: parameter replicas is not declared by the template",
+		"resourceGroups[0].steps[3]: required parameter adminPassword of template templates/region.bicep is not supplied",
+		"testdata/arm/configurations/region.parameters.json: parameters.azCount: parameter azCount has type int, but the value supplied has type string",
+		"testdata/arm/configurations/region.parameters.json: parameters.location: parameter location is not declared by the template",
+		// a parameters file that doesn't parse is reported once, without every required parameter it would supply
+		"resourceGroups[0].steps[6].parameters: testdata/arm/configurations/unparseable.bicepparam:3:18: expected a value for param regionRG, found a new line",
+		"resourceGroups[0].steps[2].variables[0].input: step regional/region has no output keyVault, only keyVaultName, zones",
+	}, failures)
+
+	template, err := LoadARMTemplate("testdata/arm/templates/region.bicep")
+	require.NoError(t, err)
+	require.Equal(t, []string{"keyVaultName", "zones"}, template.OutputNames())
+
+	_, err = NewPipelineFromFile("testdata/arm/pipeline.yaml", cfg)
+	require.NoError(t, err, "templates are only validated when asked to")
+}
+
+func TestSortedStepDependencies(t *testing.T) {
+	steps := map[StepDependency]armStep{}
+	var expected []StepDependency
+	for i := range 12 {
+		for j := range 3 {
+			id := StepDependency{ResourceGroup: fmt.Sprintf("rg%d", i), Step: fmt.Sprintf("step%d", j)}
+			steps[id] = armStep{resourceGroup: i, step: j, field: fmt.Sprintf("resourceGroups[%d].steps[%d]", i, j)}
+			expected = append(expected, id)
+		}
+	}
+	require.Equal(t, expected, sortedStepDependencies(steps), "resourceGroups[10] must sort after resourceGroups[2]")
+}
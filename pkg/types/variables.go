@@ -14,7 +14,11 @@
 
 package types
 
-import "fmt"
+import (
+	"fmt"
+
+	types2 "github.com/Azure/ARO-Tools/pkg/config/types"
+)
 
 // Variable
 // Use this to pass in values to shell steps. Pairs a value with the environment variable name.
@@ -23,7 +27,14 @@ type Variable struct {
 	Value `json:",inline"`
 }
 
+// sensitiveVariables decides which variables hold literal values that must not be printed.
+var sensitiveVariables = types2.DefaultSensitivity()
+
+// String describes the variable, redacting literal values of variables whose names mark them as sensitive.
 func (v *Variable) String() string {
+	if v.Value.Value != nil && sensitiveVariables.IsSensitive(v.Name) {
+		return fmt.Sprintf("$%s=%s", v.Name, types2.Redacted)
+	}
 	return fmt.Sprintf("$%s=%s", v.Name, v.Value.String())
 }
 